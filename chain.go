@@ -0,0 +1,245 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gouef/standards"
+	"io/fs"
+	"time"
+)
+
+// CHAIN_BACKFILL_TTL is TTL of items copied to faster tiers when TTL of found item is unknown (e.g. Redis)
+// and BackfillTTL isn't set, so faster tiers don't keep value forever
+const CHAIN_BACKFILL_TTL = time.Minute
+
+// Chain is cache composed of ordered tiers (fastest first, e.g. Memory, Redis, File).
+// Reads go through tiers until hit and back-fill faster tiers, writes and deletes go to all tiers.
+type Chain struct {
+	tiers   []standards.Cache
+	options ChainOptions
+}
+
+// ChainOptions configure Chain cache.
+type ChainOptions struct {
+	// BackfillTTL caps TTL of items copied to faster tiers, 0 means remaining TTL of found item is used
+	// and CHAIN_BACKFILL_TTL when it is unknown.
+	BackfillTTL time.Duration
+}
+
+// NewChain create new instance of Chain with tiers, first tier is read first
+func NewChain(tiers ...standards.Cache) *Chain {
+	return NewChainWithOptions(ChainOptions{}, tiers...)
+}
+
+// NewChainWithOptions create new instance of Chain with options and tiers, first tier is read first
+func NewChainWithOptions(options ChainOptions, tiers ...standards.Cache) *Chain {
+	return &Chain{
+		tiers:   tiers,
+		options: options,
+	}
+}
+
+// Tiers returns tiers of Chain
+func (c *Chain) Tiers() []standards.Cache {
+	return c.tiers
+}
+
+// NewItem create empty item of first tier, items of any tier can be saved to Chain
+func (c *Chain) NewItem(key string) standards.CacheItem {
+	if len(c.tiers) > 0 {
+		if factory, ok := c.tiers[0].(ItemFactory); ok {
+			return factory.NewItem(key)
+		}
+	}
+	return NewMemoryItem(key)
+}
+
+func (c *Chain) GetItem(key string) standards.CacheItem {
+	for i, tier := range c.tiers {
+		item := tier.GetItem(key)
+		if item == nil || !item.IsHit() {
+			continue
+		}
+		c.backfill(i, item)
+		return item
+	}
+	return nil
+}
+
+func (c *Chain) GetItems(keys ...string) []standards.CacheItem {
+	var items []standards.CacheItem
+	for _, key := range keys {
+		item := c.GetItem(key)
+		if item != nil {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (c *Chain) HasItem(key string) bool {
+	return c.GetItem(key) != nil
+}
+
+func (c *Chain) Clear() error {
+	var errs []error
+	for _, tier := range c.tiers {
+		errs = append(errs, tier.Clear())
+	}
+	return errors.Join(errs...)
+}
+
+// ClearPrefix removes items with keys starting with prefix from all tiers, all tiers must support it
+func (c *Chain) ClearPrefix(prefix string) error {
+	var errs []error
+	for _, tier := range c.tiers {
+		errs = append(errs, clearPrefixOf(tier, prefix))
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Chain) DeleteItem(key string) error {
+	var errs []error
+	for _, tier := range c.tiers {
+		// key doesn't have to be in every tier
+		if err := tier.DeleteItem(key); !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Chain) DeleteItems(keys ...string) error {
+	var errs []error
+	for _, tier := range c.tiers {
+		errs = append(errs, tier.DeleteItems(keys...))
+	}
+	return errors.Join(errs...)
+}
+
+// Save saves item to all tiers, item is converted to item type of every tier
+func (c *Chain) Save(item standards.CacheItem) error {
+	return c.saveTiers(item, func(tier standards.Cache, item standards.CacheItem) error {
+		return tier.Save(item)
+	})
+}
+
+// SaveDeferred keeps item pending in all tiers until Commit
+func (c *Chain) SaveDeferred(item standards.CacheItem) error {
+	return c.saveTiers(item, func(tier standards.Cache, item standards.CacheItem) error {
+		return tier.SaveDeferred(item)
+	})
+}
+
+// Commit commits pending items of all tiers
+func (c *Chain) Commit() error {
+	var errs []error
+	for _, tier := range c.tiers {
+		errs = append(errs, tier.Commit())
+	}
+	return errors.Join(errs...)
+}
+
+// Discard drops pending items of all tiers which support it
+func (c *Chain) Discard() error {
+	var errs []error
+	for _, tier := range c.tiers {
+		if discarder, ok := tier.(Discarder); ok {
+			errs = append(errs, discarder.Discard())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// InvalidateTags removes items with at least one of tags from all tiers which support tags
+func (c *Chain) InvalidateTags(tags ...string) error {
+	var errs []error
+	for _, tier := range c.tiers {
+		if invalidator, ok := tier.(TagInvalidator); ok {
+			errs = append(errs, invalidator.InvalidateTags(tags...))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Purge removes expired items from all tiers which support it
+func (c *Chain) Purge() (int, error) {
+	var errs []error
+	purged := 0
+	for _, tier := range c.tiers {
+		if purger, ok := tier.(Purger); ok {
+			n, err := purger.Purge()
+			purged += n
+			errs = append(errs, err)
+		}
+	}
+	return purged, errors.Join(errs...)
+}
+
+// PingContext checks health of all tiers which support it
+func (c *Chain) PingContext(ctx context.Context) error {
+	var errs []error
+	for i, tier := range c.tiers {
+		if pinger, ok := findCache[Pinger](tier); ok {
+			if err := pinger.PingContext(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("tier %d: %w", i, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SetLogOptions configure logging of all tiers which support it
+func (c *Chain) SetLogOptions(options LogOptions) {
+	for _, tier := range c.tiers {
+		if configurable, ok := findCache[LogConfigurable](tier); ok {
+			configurable.SetLogOptions(options)
+		}
+	}
+}
+
+// backfill copies item found in tier to all faster tiers, TTL is capped by BackfillTTL
+func (c *Chain) backfill(found int, item standards.CacheItem) {
+	if found == 0 {
+		return
+	}
+
+	ttl, expired := remainingTTL(item)
+	if expired {
+		return
+	}
+	if c.options.BackfillTTL > 0 && (ttl == 0 || ttl > c.options.BackfillTTL) {
+		ttl = c.options.BackfillTTL
+	}
+	if ttl == 0 {
+		ttl = CHAIN_BACKFILL_TTL
+	}
+
+	for _, tier := range c.tiers[:found] {
+		tierItem, err := copyItem(tier, item.GetKey(), item, ttl)
+		if err != nil {
+			continue
+		}
+		_ = tier.Save(tierItem)
+	}
+}
+
+// saveTiers saves item converted for every tier by save, expired item is deleted from all tiers
+func (c *Chain) saveTiers(item standards.CacheItem, save func(tier standards.Cache, item standards.CacheItem) error) error {
+	ttl, expired := remainingTTL(item)
+	if expired {
+		return c.DeleteItem(item.GetKey())
+	}
+
+	var errs []error
+	for _, tier := range c.tiers {
+		tierItem, err := copyItem(tier, item.GetKey(), item, ttl)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		errs = append(errs, save(tier, tierItem))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Codec serializes cache values.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec encodes values as JSON.
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// GobCodec encodes values by encoding/gob and keeps their Go type.
+// Custom types have to be registered by gob.Register.
+type GobCodec struct{}
+
+func (GobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	// pointer to interface, so concrete type is encoded too
+	if err := gob.NewEncoder(&buf).Encode(&v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (GobCodec) Unmarshal(data []byte, v any) error {
+	var value any
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&value); err != nil {
+		return err
+	}
+	return assign(v, value)
+}
+
+// RawCodec passes []byte and string values without any encoding, values are read back as []byte.
+type RawCodec struct{}
+
+func (RawCodec) Marshal(v any) ([]byte, error) {
+	switch data := v.(type) {
+	case []byte:
+		return data, nil
+	case string:
+		return []byte(data), nil
+	}
+	return nil, fmt.Errorf("cache: raw codec can't marshal %T", v)
+}
+
+func (RawCodec) Unmarshal(data []byte, v any) error {
+	switch target := v.(type) {
+	case *[]byte:
+		*target = append([]byte(nil), data...)
+	case *string:
+		*target = string(data)
+	case *any:
+		*target = append([]byte(nil), data...)
+	default:
+		return fmt.Errorf("cache: raw codec can't unmarshal to %T", v)
+	}
+	return nil
+}
+
+// assign set value to target pointer
+func assign(target any, value any) error {
+	t := reflect.ValueOf(target)
+	if t.Kind() != reflect.Pointer || t.IsNil() {
+		return fmt.Errorf("cache: can't unmarshal to %T", target)
+	}
+
+	if value == nil {
+		t.Elem().SetZero()
+		return nil
+	}
+
+	v := reflect.ValueOf(value)
+	if !v.Type().AssignableTo(t.Elem().Type()) {
+		return fmt.Errorf("cache: can't assign %s to %s", v.Type(), t.Elem().Type())
+	}
+	t.Elem().Set(v)
+	return nil
+}
@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"context"
+	"github.com/gouef/standards"
+)
+
+// ContextCache is cache with operations which take context for deadlines and cancellation.
+// Reads return nil (miss) and writes return ctx.Err() when context is done.
+type ContextCache interface {
+	standards.Cache
+	GetItemContext(ctx context.Context, key string) standards.CacheItem
+	GetItemsContext(ctx context.Context, keys ...string) []standards.CacheItem
+	HasItemContext(ctx context.Context, key string) bool
+	ClearContext(ctx context.Context) error
+	DeleteItemContext(ctx context.Context, key string) error
+	DeleteItemsContext(ctx context.Context, keys ...string) error
+	SaveContext(ctx context.Context, item standards.CacheItem) error
+	SaveDeferredContext(ctx context.Context, item standards.CacheItem) error
+	CommitContext(ctx context.Context) error
+}
+
+// Discarder is cache which can drop items saved by SaveDeferred before Commit.
+type Discarder interface {
+	Discard() error
+}
+
+// Pinger is cache which can check health of its backend.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// contextCache adapts cache without context support to ContextCache, context is checked before every operation
+type contextCache struct {
+	standards.Cache
+}
+
+// contextOf returns cache as ContextCache, cache without context support is adapted by contextCache
+func contextOf(cache standards.Cache) ContextCache {
+	if ctxCache, ok := cache.(ContextCache); ok {
+		return ctxCache
+	}
+	return contextCache{Cache: cache}
+}
+
+func (c contextCache) GetItemContext(ctx context.Context, key string) standards.CacheItem {
+	if ctx.Err() != nil {
+		return nil
+	}
+	return c.GetItem(key)
+}
+
+func (c contextCache) GetItemsContext(ctx context.Context, keys ...string) []standards.CacheItem {
+	if ctx.Err() != nil {
+		return nil
+	}
+	return c.GetItems(keys...)
+}
+
+func (c contextCache) HasItemContext(ctx context.Context, key string) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	return c.HasItem(key)
+}
+
+func (c contextCache) ClearContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Clear()
+}
+
+func (c contextCache) DeleteItemContext(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.DeleteItem(key)
+}
+
+func (c contextCache) DeleteItemsContext(ctx context.Context, keys ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.DeleteItems(keys...)
+}
+
+func (c contextCache) SaveContext(ctx context.Context, item standards.CacheItem) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Save(item)
+}
+
+func (c contextCache) SaveDeferredContext(ctx context.Context, item standards.CacheItem) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.SaveDeferred(item)
+}
+
+func (c contextCache) CommitContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Commit()
+}
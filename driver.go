@@ -0,0 +1,349 @@
+package cache
+
+import (
+	"fmt"
+	"github.com/gouef/standards"
+	redisLib "github.com/redis/go-redis/v9"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Factory creates cache of DSN, options are read by DSN methods, options which weren't read are rejected.
+type Factory func(dsn *DSN) (standards.Cache, error)
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]Factory)
+)
+
+func init() {
+	RegisterDriver("memory", openMemory)
+	RegisterDriver("file", openFile)
+	RegisterDriver("redis", openRedis)
+	RegisterDriver("rediss", openRedis)
+	RegisterDriver("chain", openChain)
+}
+
+// RegisterDriver makes factory available by name, name is scheme of DSN.
+// It panics when name is registered twice or factory is nil.
+func RegisterDriver(name string, factory Factory) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	if factory == nil {
+		panic("cache: RegisterDriver factory is nil")
+	}
+	if _, exists := drivers[name]; exists {
+		panic("cache: RegisterDriver called twice for driver " + name)
+	}
+	drivers[name] = factory
+}
+
+// Drivers returns sorted names of registered drivers
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DSN is parsed DSN passed to Factory
+type DSN struct {
+	*url.URL
+	values url.Values
+	used   []string
+	lookup func(name string) (standards.Cache, bool)
+}
+
+func openDSN(dsn string, lookup func(name string) (standards.Cache, bool)) (standards.Cache, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	if u.Scheme == "" {
+		return nil, fmt.Errorf("%w: missing scheme in DSN \"%s\"", ErrInvalidConfig, dsn)
+	}
+
+	driversMu.RLock()
+	factory, exists := drivers[u.Scheme]
+	driversMu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("%w: unknown driver \"%s\"", ErrInvalidConfig, u.Scheme)
+	}
+
+	parsed := &DSN{URL: u, values: u.Query(), lookup: lookup}
+	cache, err := factory(parsed)
+	if err == nil {
+		err = parsed.unused()
+	}
+	if err != nil {
+		if cache != nil {
+			_ = closeCache(cache)
+		}
+		return nil, err
+	}
+	return cache, nil
+}
+
+// Get returns option of name, empty when it isn't set
+func (d *DSN) Get(name string) string {
+	d.used = append(d.used, name)
+	return d.values.Get(name)
+}
+
+// Int returns integer option of name, 0 when it isn't set
+func (d *DSN) Int(name string) (int, error) {
+	value := d.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: option \"%s\" must be integer, got \"%s\"", ErrInvalidConfig, name, value)
+	}
+	return n, nil
+}
+
+// Int64 returns non-negative integer option of name, 0 when it isn't set
+func (d *DSN) Int64(name string) (int64, error) {
+	value := d.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%w: option \"%s\" must be non-negative integer, got \"%s\"", ErrInvalidConfig, name, value)
+	}
+	return n, nil
+}
+
+// Duration returns non-negative duration option of name (e.g. 1m30s), 0 when it isn't set
+func (d *DSN) Duration(name string) (time.Duration, error) {
+	value := d.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("%w: option \"%s\" must be non-negative duration, got \"%s\"", ErrInvalidConfig, name, value)
+	}
+	return duration, nil
+}
+
+// Float64 returns non-negative float option of name, 0 when it isn't set
+func (d *DSN) Float64(name string) (float64, error) {
+	value := d.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("%w: option \"%s\" must be non-negative number, got \"%s\"", ErrInvalidConfig, name, value)
+	}
+	return f, nil
+}
+
+// Bool returns boolean option of name, false when it isn't set
+func (d *DSN) Bool(name string) (bool, error) {
+	value := d.Get(name)
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%w: option \"%s\" must be boolean, got \"%s\"", ErrInvalidConfig, name, value)
+	}
+	return b, nil
+}
+
+// Codec returns codec of option "codec" (json, gob, raw), nil when it isn't set
+func (d *DSN) Codec() (Codec, error) {
+	switch codec := d.Get("codec"); codec {
+	case "":
+		return nil, nil
+	case "json":
+		return JSONCodec{}, nil
+	case "gob":
+		return GobCodec{}, nil
+	case "raw":
+		return RawCodec{}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown codec \"%s\"", ErrInvalidConfig, codec)
+	}
+}
+
+// Lookup returns other cache of Storage by name, error wraps ErrUnknownCache when it doesn't exist (yet)
+func (d *DSN) Lookup(name string) (standards.Cache, error) {
+	if d.lookup == nil {
+		return nil, fmt.Errorf("%w: cache \"%s\" can be referenced only by DSN opened by Storage", ErrInvalidConfig, name)
+	}
+	cache, exists := d.lookup(name)
+	if !exists {
+		return nil, fmt.Errorf("%w \"%s\"", ErrUnknownCache, name)
+	}
+	return cache, nil
+}
+
+// Rest returns options which weren't read yet and marks them as read, e.g. for passing them to other parser
+func (d *DSN) Rest() url.Values {
+	rest := url.Values{}
+	for name, values := range d.values {
+		if !d.isUsed(name) {
+			rest[name] = values
+			d.used = append(d.used, name)
+		}
+	}
+	return rest
+}
+
+// unused returns error when some option wasn't read
+func (d *DSN) unused() error {
+	rest := d.Rest()
+	if len(rest) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(rest))
+	for name := range rest {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("%w: unknown options %s", ErrInvalidConfig, strings.Join(names, ", "))
+}
+
+func (d *DSN) isUsed(name string) bool {
+	for _, used := range d.used {
+		if used == name {
+			return true
+		}
+	}
+	return false
+}
+
+// closeCache closes cache implementing io.Closer, also decorated one
+func closeCache(cache standards.Cache) error {
+	if closer, ok := findCache[io.Closer](cache); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func openMemory(dsn *DSN) (standards.Cache, error) {
+	var options MemoryOptions
+	var err error
+
+	if options.MaxEntries, err = dsn.Int("max_entries"); err != nil {
+		return nil, err
+	}
+	if options.MaxBytes, err = dsn.Int64("max_bytes"); err != nil {
+		return nil, err
+	}
+	if options.CleanupInterval, err = dsn.Duration("cleanup"); err != nil {
+		return nil, err
+	}
+	if options.XFetchBeta, err = dsn.Float64("xfetch_beta"); err != nil {
+		return nil, err
+	}
+	switch policy := dsn.Get("policy"); policy {
+	case "", "lru":
+	case "lfu":
+		options.Policy = NewLFUPolicy()
+	case "fifo":
+		options.Policy = NewFIFOPolicy()
+	case "random":
+		options.Policy = NewRandomPolicy()
+	default:
+		return nil, fmt.Errorf("%w: unknown policy \"%s\"", ErrInvalidConfig, policy)
+	}
+
+	return NewMemoryWithOptions(options), nil
+}
+
+func openFile(dsn *DSN) (standards.Cache, error) {
+	dir := dsn.Path
+	if dsn.Host != "" {
+		// relative path, e.g. file://cache/app
+		dir = dsn.Host + dsn.Path
+	}
+	if dir == "" {
+		return nil, fmt.Errorf("%w: missing directory", ErrInvalidConfig)
+	}
+
+	var options FileOptions
+	var err error
+	if options.ShardDepth, err = dsn.Int("shard"); err != nil {
+		return nil, err
+	}
+	if options.Codec, err = dsn.Codec(); err != nil {
+		return nil, err
+	}
+	if options.XFetchBeta, err = dsn.Float64("xfetch_beta"); err != nil {
+		return nil, err
+	}
+	fileCache, err := NewFileWithOptions(dir, options)
+	if err != nil {
+		return nil, err
+	}
+	return fileCache, nil
+}
+
+func openRedis(dsn *DSN) (standards.Cache, error) {
+	var options RedisOptions
+	var err error
+
+	options.Prefix = dsn.Get("prefix")
+	if options.Codec, err = dsn.Codec(); err != nil {
+		return nil, err
+	}
+	if options.XFetchBeta, err = dsn.Float64("xfetch_beta"); err != nil {
+		return nil, err
+	}
+	if options.SlidingExpiration, err = dsn.Bool("sliding"); err != nil {
+		return nil, err
+	}
+	if options.DisableFlushDB, err = dsn.Bool("disable_flushdb"); err != nil {
+		return nil, err
+	}
+
+	// rest of options is parsed by go-redis
+	u := *dsn.URL
+	u.RawQuery = dsn.Rest().Encode()
+	clientOptions, err := redisLib.ParseURL(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	redisCache := NewRedisWithOptions(redisLib.NewClient(clientOptions), options)
+	redisCache.ownsClient = true
+	return redisCache, nil
+}
+
+func openChain(dsn *DSN) (standards.Cache, error) {
+	var options ChainOptions
+	var err error
+	if options.BackfillTTL, err = dsn.Duration("backfill_ttl"); err != nil {
+		return nil, err
+	}
+
+	names := dsn.Get("tiers")
+	if names == "" {
+		return nil, fmt.Errorf("%w: missing tiers", ErrInvalidConfig)
+	}
+
+	var tiers []standards.Cache
+	for _, name := range strings.Split(names, ",") {
+		tier, err := dsn.Lookup(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		tiers = append(tiers, tier)
+	}
+	return NewChainWithOptions(options, tiers...), nil
+}
@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"errors"
+	"github.com/gouef/standards"
+)
+
+// ErrInvalidKey is returned when key is rejected by ValidateMiddleware.
+var ErrInvalidKey = errors.New("invalid cache key")
+
+var (
+	// ErrInvalidConfig is returned when DSN or config of Storage is invalid.
+	ErrInvalidConfig = errors.New("invalid cache config")
+	// ErrUnknownCache is returned when DSN references cache which isn't in Storage (yet), e.g. chain tier.
+	ErrUnknownCache = errors.New("unknown cache")
+	// ErrFlushNotAllowed is returned by Clear of Redis without Prefix when DisableFlushDB is set.
+	ErrFlushNotAllowed = errors.New("flush of whole redis database is not allowed")
+	// ErrNotSupported is returned when cache doesn't support operation, e.g. Clear of PrefixCache without PrefixClearer.
+	ErrNotSupported = errors.New("operation not supported by cache")
+)
+
+var (
+	// ErrMiss is returned by error-returning reads when key is not in cache or is expired.
+	ErrMiss = errors.New("cache miss")
+	// ErrBackendUnavailable wraps errors of backend, e.g. Redis connection error or file permission error.
+	ErrBackendUnavailable = errors.New("cache backend unavailable")
+	// ErrCorruptEntry wraps errors of entries which can't be decoded.
+	ErrCorruptEntry = errors.New("corrupt cache entry")
+)
+
+// CheckedReader is cache which distinguishes misses from backend errors.
+// GetItemE returns nil error only for hit, errors can be checked by errors.Is with ErrMiss,
+// ErrBackendUnavailable and ErrCorruptEntry.
+type CheckedReader interface {
+	GetItemE(key string) (standards.CacheItem, error)
+	HasItemE(key string) (bool, error)
+}
+
+// hitOrMiss returns item when it is hit, otherwise ErrMiss
+func hitOrMiss(item standards.CacheItem) (standards.CacheItem, error) {
+	if item == nil || !item.IsHit() {
+		return nil, ErrMiss
+	}
+	return item, nil
+}
+
+// hasItem converts result of GetItemE to result of HasItemE
+func hasItem(_ standards.CacheItem, err error) (bool, error) {
+	if errors.Is(err, ErrMiss) {
+		return false, nil
+	}
+	return err == nil, err
+}
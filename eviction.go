@@ -0,0 +1,227 @@
+package cache
+
+import (
+	"container/list"
+	"math/rand"
+)
+
+// EvictionPolicy decides which key is removed when a bounded Memory cache is full.
+// Policies are always called while the cache holds its lock, so they don't need their own locking.
+type EvictionPolicy interface {
+	// Add registers newly stored key.
+	Add(key string)
+	// Access is called when existing key is read or overwritten.
+	Access(key string)
+	// Remove forgets key which was deleted from cache.
+	Remove(key string)
+	// Victim returns key which should be evicted next.
+	Victim() (key string, ok bool)
+}
+
+// LRUPolicy evicts the least recently used key.
+type LRUPolicy struct {
+	order *list.List
+	keys  map[string]*list.Element
+}
+
+// NewLRUPolicy create new instance of LRUPolicy
+func NewLRUPolicy() *LRUPolicy {
+	return &LRUPolicy{
+		order: list.New(),
+		keys:  make(map[string]*list.Element),
+	}
+}
+
+func (p *LRUPolicy) Add(key string) {
+	if e, exists := p.keys[key]; exists {
+		p.order.MoveToFront(e)
+		return
+	}
+	p.keys[key] = p.order.PushFront(key)
+}
+
+func (p *LRUPolicy) Access(key string) {
+	if e, exists := p.keys[key]; exists {
+		p.order.MoveToFront(e)
+	}
+}
+
+func (p *LRUPolicy) Remove(key string) {
+	if e, exists := p.keys[key]; exists {
+		p.order.Remove(e)
+		delete(p.keys, key)
+	}
+}
+
+func (p *LRUPolicy) Victim() (string, bool) {
+	e := p.order.Back()
+	if e == nil {
+		return "", false
+	}
+	return e.Value.(string), true
+}
+
+// FIFOPolicy evicts the oldest stored key, reads don't change the order.
+type FIFOPolicy struct {
+	order *list.List
+	keys  map[string]*list.Element
+}
+
+// NewFIFOPolicy create new instance of FIFOPolicy
+func NewFIFOPolicy() *FIFOPolicy {
+	return &FIFOPolicy{
+		order: list.New(),
+		keys:  make(map[string]*list.Element),
+	}
+}
+
+func (p *FIFOPolicy) Add(key string) {
+	if _, exists := p.keys[key]; exists {
+		return
+	}
+	p.keys[key] = p.order.PushFront(key)
+}
+
+func (p *FIFOPolicy) Access(key string) {}
+
+func (p *FIFOPolicy) Remove(key string) {
+	if e, exists := p.keys[key]; exists {
+		p.order.Remove(e)
+		delete(p.keys, key)
+	}
+}
+
+func (p *FIFOPolicy) Victim() (string, bool) {
+	e := p.order.Back()
+	if e == nil {
+		return "", false
+	}
+	return e.Value.(string), true
+}
+
+// LFUPolicy evicts the least frequently used key, ties are broken by age (oldest first).
+type LFUPolicy struct {
+	keys    map[string]*list.Element
+	buckets map[uint64]*list.List
+	min     uint64
+}
+
+type lfuEntry struct {
+	key   string
+	count uint64
+}
+
+// NewLFUPolicy create new instance of LFUPolicy
+func NewLFUPolicy() *LFUPolicy {
+	return &LFUPolicy{
+		keys:    make(map[string]*list.Element),
+		buckets: make(map[uint64]*list.List),
+	}
+}
+
+func (p *LFUPolicy) Add(key string) {
+	if _, exists := p.keys[key]; exists {
+		p.Access(key)
+		return
+	}
+	p.keys[key] = p.bucket(1).PushFront(&lfuEntry{key: key, count: 1})
+	p.min = 1
+}
+
+func (p *LFUPolicy) Access(key string) {
+	e, exists := p.keys[key]
+	if !exists {
+		return
+	}
+	entry := e.Value.(*lfuEntry)
+	p.unlink(e)
+	if p.buckets[entry.count] == nil && p.min == entry.count {
+		p.min++
+	}
+	entry.count++
+	p.keys[key] = p.bucket(entry.count).PushFront(entry)
+}
+
+func (p *LFUPolicy) Remove(key string) {
+	if e, exists := p.keys[key]; exists {
+		p.unlink(e)
+		delete(p.keys, key)
+	}
+}
+
+func (p *LFUPolicy) Victim() (string, bool) {
+	if len(p.keys) == 0 {
+		return "", false
+	}
+	b, exists := p.buckets[p.min]
+	if !exists {
+		first := true
+		for count := range p.buckets {
+			if first || count < p.min {
+				p.min, first = count, false
+			}
+		}
+		b = p.buckets[p.min]
+	}
+	return b.Back().Value.(*lfuEntry).key, true
+}
+
+func (p *LFUPolicy) bucket(count uint64) *list.List {
+	b, exists := p.buckets[count]
+	if !exists {
+		b = list.New()
+		p.buckets[count] = b
+	}
+	return b
+}
+
+func (p *LFUPolicy) unlink(e *list.Element) {
+	count := e.Value.(*lfuEntry).count
+	b := p.buckets[count]
+	b.Remove(e)
+	if b.Len() == 0 {
+		delete(p.buckets, count)
+	}
+}
+
+// RandomPolicy evicts a random key.
+type RandomPolicy struct {
+	keys  []string
+	index map[string]int
+}
+
+// NewRandomPolicy create new instance of RandomPolicy
+func NewRandomPolicy() *RandomPolicy {
+	return &RandomPolicy{
+		index: make(map[string]int),
+	}
+}
+
+func (p *RandomPolicy) Add(key string) {
+	if _, exists := p.index[key]; exists {
+		return
+	}
+	p.index[key] = len(p.keys)
+	p.keys = append(p.keys, key)
+}
+
+func (p *RandomPolicy) Access(key string) {}
+
+func (p *RandomPolicy) Remove(key string) {
+	i, exists := p.index[key]
+	if !exists {
+		return
+	}
+	last := len(p.keys) - 1
+	p.keys[i] = p.keys[last]
+	p.index[p.keys[i]] = i
+	p.keys = p.keys[:last]
+	delete(p.index, key)
+}
+
+func (p *RandomPolicy) Victim() (string, bool) {
+	if len(p.keys) == 0 {
+		return "", false
+	}
+	return p.keys[rand.Intn(len(p.keys))], true
+}
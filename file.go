@@ -1,118 +1,275 @@
 package cache
 
 import (
-	"encoding/json"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"github.com/gouef/standards"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type File struct {
 	Dir string
 	Mu  sync.RWMutex
+	// Codec serializes values, nil means whole item is stored as JSON.
+	Codec Codec
+	// ShardDepth is count of directory levels of cache files, 0 means FILE_SHARD_DEPTH, negative means no directories.
+	ShardDepth int
+	// XFetchBeta enables probabilistic early expiration (XFetch) of items with compute time, 0 means disabled.
+	XFetchBeta float64
+	// deferred items waiting for Commit
+	deferred map[string]*FileItem
+	// expirations count removed expired files
+	expirations atomic.Uint64
+	log         cacheLog
+	lock        dirLock
+}
+
+// FileOptions configure File and FileSimple cache.
+type FileOptions struct {
+	// Codec serializes values, nil means whole item is stored as JSON.
+	Codec Codec
+	// ShardDepth is count of directory levels of cache files, 0 means FILE_SHARD_DEPTH, negative means no directories.
+	ShardDepth int
+	// Log configures logging of errors, corrupt files and slow operations.
+	Log LogOptions
+	// XFetchBeta enables probabilistic early expiration (XFetch) of items with compute time, 0 means disabled.
+	XFetchBeta float64
 }
 
 const FILE_EXTENSION = ".cache"
 
+// FILE_SHARD_DEPTH is default count of directory levels of cache files (e.g. ab/cd/<hash>.cache)
+const FILE_SHARD_DEPTH = 2
+
+// FILE_SLIDE_DIVISOR limits rewriting of sliding items, hit rewrites file only when it moves expiration
+// forward by more than idle timeout / FILE_SLIDE_DIVISOR
+const FILE_SLIDE_DIVISOR = 10
+
+// TAGS_DIR is directory inside cache directory with tag index files
+const TAGS_DIR = ".tags"
+
 // NewFile create new instance of File and check if directory exists.
 func NewFile(dir string) (standards.Cache, error) {
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return nil, err
-		}
+	return NewFileWithOptions(dir, FileOptions{})
+}
+
+// NewFileWithOptions create new instance of File with options and check if directory exists.
+func NewFileWithOptions(dir string, options FileOptions) (*File, error) {
+	if err := createDir(dir); err != nil {
+		return nil, err
 	}
-	return &File{Dir: dir}, nil
+	createLockFile(dir)
+	c := &File{
+		Dir:        dir,
+		Codec:      options.Codec,
+		ShardDepth: options.ShardDepth,
+		XFetchBeta: options.XFetchBeta,
+	}
+	c.log.set(options.Log)
+	return c, nil
+}
+
+// SetLogOptions configure logging of errors, corrupt files and slow operations
+func (c *File) SetLogOptions(options LogOptions) {
+	c.log.set(options)
+}
+
+// NewItem create empty item for File cache
+func (c *File) NewItem(key string) standards.CacheItem {
+	return NewFileItem(key)
 }
 
 func (c *File) GetItem(key string) standards.CacheItem {
+	item, _ := c.GetItemE(key)
+	return item
+}
+
+// GetItemE returns item of key, error is ErrMiss for missing or expired file, wraps ErrBackendUnavailable
+// when file can't be read and ErrCorruptEntry when file can't be decoded
+func (c *File) GetItemE(key string) (standards.CacheItem, error) {
+	defer c.log.slow("GetItem", key, time.Now())
 	c.Mu.RLock()
 	defer c.Mu.RUnlock()
 
+	if item, exists := c.deferred[key]; exists {
+		return hitOrMiss(item)
+	}
+
+	unlock, err := c.lock.lock(c.Dir, false)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBackendUnavailable, c.log.failed("GetItem", key, err))
+	}
+	defer unlock()
+
 	filePath := c.getFilePath(key)
 	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return nil, ErrMiss
+	}
 	if err != nil {
-		return nil
+		return nil, fmt.Errorf("%w: %w", ErrBackendUnavailable, c.log.failed("GetItem", key, err))
 	}
 
-	var item *FileItem
-	if err := json.Unmarshal(data, &item); err != nil {
+	item, err := decodeFileItem(data, c.Codec)
+	if err != nil {
+		c.log.corrupt("GetItem", key, err)
 		_ = os.Remove(filePath)
-		return nil
+		return nil, fmt.Errorf("%w: %w", ErrCorruptEntry, err)
 	}
 
 	if !item.KeepTTL && item.Expiration.Before(time.Now()) && !item.Expiration.IsZero() {
 		_ = os.Remove(filePath)
-		return nil
+		c.expirations.Add(1)
+		return nil, ErrMiss
+	}
+	now := time.Now()
+	if expiresEarly(item, c.XFetchBeta, now) {
+		return nil, ErrMiss
+	}
+	if item.slide(now) {
+		_ = c.log.failed("GetItem", key, writeSlidItem(filePath, item, c.Codec))
 	}
 
-	return item
+	return item, nil
 }
 
 func (c *File) GetItems(keys ...string) []standards.CacheItem {
-	c.Mu.RLock()
-	defer c.Mu.RUnlock()
-
 	var items []standards.CacheItem
 	for _, key := range keys {
 		item := c.GetItem(key)
 		if item != nil {
-			items = append(items, c.GetItem(key))
+			items = append(items, item)
 		}
 	}
 	return items
 }
 
 func (c *File) HasItem(key string) bool {
-	item := c.GetItem(key)
+	has, _ := c.HasItemE(key)
+	return has
+}
 
-	if item == nil {
-		return false
+// HasItemE reports if key is in cache, misses are not errors
+func (c *File) HasItemE(key string) (bool, error) {
+	return hasItem(c.GetItemE(key))
+}
+
+func (c *File) Clear() error {
+	c.Mu.Lock()
+	defer c.Mu.Unlock()
+
+	c.deferred = nil
+
+	unlock, err := c.lock.lock(c.Dir, true)
+	if err != nil {
+		return c.log.failed("Clear", "", err)
 	}
+	defer unlock()
 
-	return item.IsHit()
+	return c.log.failed("Clear", "", clearDir(c.Dir))
 }
 
-func (c *File) Clear() error {
+// ClearPrefix removes items with keys starting with prefix, every cache file is read to find its key
+func (c *File) ClearPrefix(prefix string) error {
 	c.Mu.Lock()
 	defer c.Mu.Unlock()
 
-	files, err := os.ReadDir(c.Dir)
+	for key := range c.deferred {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.deferred, key)
+		}
+	}
+
+	unlock, err := c.lock.lock(c.Dir, true)
 	if err != nil {
-		return err
+		return c.log.failed("ClearPrefix", prefix, err)
 	}
+	defer unlock()
 
-	for _, file := range files {
-		name := strings.Replace(file.Name(), FILE_EXTENSION, "", 1)
-		err := os.Remove(c.getFilePath(name))
+	err = walkCacheFiles(c.Dir, func(filePath string) error {
+		data, err := os.ReadFile(filePath)
+		if os.IsNotExist(err) {
+			return nil
+		}
 		if err != nil {
 			return err
 		}
+		// corrupt file can't be matched by key, it is removed by Purge
+		item, err := decodeFileItem(data, c.Codec)
+		if err != nil || !strings.HasPrefix(item.Key, prefix) {
+			return nil
+		}
+		if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	})
+	if err == nil {
+		err = c.compactTagIndexes()
 	}
-	return nil
+	return c.log.failed("ClearPrefix", prefix, err)
 }
 
 func (c *File) DeleteItem(key string) error {
 	c.Mu.Lock()
 	defer c.Mu.Unlock()
 
-	return os.Remove(c.getFilePath(key))
+	unlock, err := c.lock.lock(c.Dir, true)
+	if err != nil {
+		return c.log.failed("DeleteItem", key, err)
+	}
+	defer unlock()
+
+	_, pending := c.deferred[key]
+	delete(c.deferred, key)
+
+	if err := c.unindexTags(key); err != nil {
+		_ = c.log.failed("DeleteItem", key, err)
+	}
+	err = os.Remove(c.getFilePath(key))
+	if os.IsNotExist(err) {
+		if pending {
+			return nil
+		}
+		return err
+	}
+	return c.log.failed("DeleteItem", key, err)
 }
 
 func (c *File) DeleteItems(keys ...string) error {
 	c.Mu.Lock()
 	defer c.Mu.Unlock()
 
+	unlock, err := c.lock.lock(c.Dir, true)
+	if err != nil {
+		return c.log.failed("DeleteItems", "", err)
+	}
+	defer unlock()
+
 	for _, key := range keys {
-		_ = os.Remove(c.getFilePath(key))
+		delete(c.deferred, key)
+		if err := c.unindexTags(key); err != nil {
+			_ = c.log.failed("DeleteItems", key, err)
+		}
+		if err := os.Remove(c.getFilePath(key)); err != nil && !os.IsNotExist(err) {
+			_ = c.log.failed("DeleteItems", key, err)
+		}
 	}
 	return nil
 }
 
 func (c *File) Save(item standards.CacheItem) error {
+	start := time.Now()
 	c.Mu.Lock()
 	defer c.Mu.Unlock()
 
@@ -120,23 +277,433 @@ func (c *File) Save(item standards.CacheItem) error {
 	if !ok {
 		return errors.New("invalid cache item type")
 	}
+	defer c.log.slow("Save", fItem.Key, start)
+
+	delete(c.deferred, fItem.Key)
 
-	data, err := json.Marshal(fItem)
+	unlock, err := c.lock.lock(c.Dir, true)
 	if err != nil {
-		return err
+		return c.log.failed("Save", fItem.Key, err)
 	}
+	defer unlock()
 
-	return os.WriteFile(c.getFilePath(fItem.Key), data, 0644)
+	return c.log.failed("Save", fItem.Key, c.save(fItem))
 }
 
+// SaveDeferred keeps item pending until Commit, pending item is returned by GetItem
 func (c *File) SaveDeferred(item standards.CacheItem) error {
-	return c.Save(item)
+	c.Mu.Lock()
+	defer c.Mu.Unlock()
+
+	fItem, ok := item.(*FileItem)
+	if !ok {
+		return errors.New("invalid cache item type")
+	}
+
+	if c.deferred == nil {
+		c.deferred = make(map[string]*FileItem)
+	}
+	c.deferred[fItem.Key] = fItem
+	return nil
 }
 
+// Commit writes all pending items in one locked pass, items which failed stay pending
 func (c *File) Commit() error {
+	c.Mu.Lock()
+	defer c.Mu.Unlock()
+
+	if len(c.deferred) == 0 {
+		return nil
+	}
+	defer c.log.slow("Commit", "", time.Now())
+
+	unlock, err := c.lock.lock(c.Dir, true)
+	if err != nil {
+		return c.log.failed("Commit", "", err)
+	}
+	defer unlock()
+
+	var errs []error
+	for key, item := range c.deferred {
+		if err := c.save(item); err != nil {
+			errs = append(errs, c.log.failed("Commit", key, err))
+			continue
+		}
+		delete(c.deferred, key)
+	}
+	return errors.Join(errs...)
+}
+
+// Discard drops all pending items
+func (c *File) Discard() error {
+	c.Mu.Lock()
+	defer c.Mu.Unlock()
+
+	c.deferred = nil
+	return nil
+}
+
+// InvalidateTags removes all items with at least one of tags
+func (c *File) InvalidateTags(tags ...string) error {
+	c.Mu.Lock()
+	defer c.Mu.Unlock()
+
+	for key, item := range c.deferred {
+		if hasAnyTag(item.Tags, tags) {
+			delete(c.deferred, key)
+		}
+	}
+
+	unlock, err := c.lock.lock(c.Dir, true)
+	if err != nil {
+		return c.log.failed("InvalidateTags", "", err)
+	}
+	defer unlock()
+
+	return c.log.failed("InvalidateTags", "", c.invalidateTags(tags))
+}
+
+// invalidateTags removes items of tags, caller must hold the locks
+func (c *File) invalidateTags(tags []string) error {
+	for _, tag := range tags {
+		indexPath := c.getTagPath(tag)
+		keys, err := readTagIndex(indexPath)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			filePath := c.getFilePath(key)
+			data, err := os.ReadFile(filePath)
+			if err != nil {
+				continue
+			}
+			// item could be saved again without the tag
+			if item, err := decodeFileItem(data, c.Codec); err == nil && !slices.Contains(item.Tags, tag) {
+				continue
+			}
+			if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+
+		if err := os.Remove(indexPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
 	return nil
 }
 
+// Purge removes expired and corrupted cache files
+func (c *File) Purge() (int, error) {
+	c.Mu.Lock()
+	defer c.Mu.Unlock()
+
+	unlock, err := c.lock.lock(c.Dir, true)
+	if err != nil {
+		return 0, c.log.failed("Purge", "", err)
+	}
+	defer unlock()
+
+	purged, err := purgeDir(c.Dir, c.Codec, &c.log)
+	c.expirations.Add(uint64(purged))
+	if err == nil {
+		err = c.compactTagIndexes()
+	}
+	return purged, c.log.failed("Purge", "", err)
+}
+
+// Close closes lock file of cache directory, cache can be used again
+func (c *File) Close() error {
+	return c.lock.close()
+}
+
+// Expirations returns count of expired files removed by GetItem and Purge (Purge counts corrupted files too)
+func (c *File) Expirations() uint64 {
+	return c.expirations.Load()
+}
+
+// save writes item to its file, caller must hold the locks
+func (c *File) save(item *FileItem) error {
+	data, err := encodeFileItem(item, c.Codec)
+	if err != nil {
+		return err
+	}
+
+	if err := writeCacheFile(c.getFilePath(item.Key), data); err != nil {
+		return err
+	}
+	return c.indexTags(item.Key, item.Tags)
+}
+
+// writeSlidItem writes item with expiration pushed forward by hit, caller must hold read locks.
+// Concurrent readers write expirations computed from their own time, the last rename wins and every
+// written expiration is valid, writers are excluded by the locks.
+func writeSlidItem(filePath string, item *FileItem, codec Codec) error {
+	data, err := encodeFileItem(item, codec)
+	if err != nil {
+		return err
+	}
+	return writeCacheFile(filePath, data)
+}
+
+// FilePath returns path of cache file of key
+func (c *File) FilePath(key string) string {
+	return c.getFilePath(key)
+}
+
 func (c *File) getFilePath(key string) string {
-	return filepath.Join(c.Dir, key+FILE_EXTENSION)
+	return keyPath(c.Dir, key, c.ShardDepth)
+}
+
+// indexTags appends key to index file of every tag which doesn't contain it yet
+func (c *File) indexTags(key string, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Join(c.Dir, TAGS_DIR), 0755); err != nil {
+		return err
+	}
+
+	line := strconv.Quote(key) + "\n"
+	for _, tag := range tags {
+		indexPath := c.getTagPath(tag)
+		keys, err := readTagIndex(indexPath)
+		if err != nil {
+			return err
+		}
+		if slices.Contains(keys, key) {
+			continue
+		}
+
+		f, err := os.OpenFile(indexPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		_, err = f.WriteString(line)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *File) getTagPath(tag string) string {
+	hash := sha256.Sum256([]byte(tag))
+	return filepath.Join(c.Dir, TAGS_DIR, hex.EncodeToString(hash[:])+".tag")
+}
+
+// unindexTags removes key from index files of tags of its cache file, caller must hold the locks
+func (c *File) unindexTags(key string) error {
+	data, err := os.ReadFile(c.getFilePath(key))
+	if err != nil {
+		// missing file has no tags, corrupted one is skipped by InvalidateTags
+		return nil
+	}
+	item, err := decodeFileItem(data, c.Codec)
+	if err != nil {
+		return nil
+	}
+
+	for _, tag := range item.Tags {
+		indexPath := c.getTagPath(tag)
+		keys, err := readTagIndex(indexPath)
+		if err != nil {
+			return err
+		}
+		if err := writeTagIndex(indexPath, slices.DeleteFunc(keys, func(k string) bool { return k == key }), len(keys)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compactTagIndexes removes keys without cache file from all tag index files, caller must hold the locks
+func (c *File) compactTagIndexes() error {
+	entries, err := os.ReadDir(filepath.Join(c.Dir, TAGS_DIR))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".tag") {
+			continue
+		}
+		indexPath := filepath.Join(c.Dir, TAGS_DIR, entry.Name())
+		keys, err := readTagIndex(indexPath)
+		if err != nil {
+			return err
+		}
+		count := len(keys)
+		keys = slices.DeleteFunc(keys, func(key string) bool {
+			_, err := os.Stat(c.getFilePath(key))
+			return os.IsNotExist(err)
+		})
+		if err := writeTagIndex(indexPath, keys, count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readTagIndex returns unique keys stored in tag index file
+func readTagIndex(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	seen := make(map[string]struct{})
+	for _, line := range strings.Split(string(data), "\n") {
+		key, err := strconv.Unquote(line)
+		if err != nil {
+			continue
+		}
+		if _, exists := seen[key]; exists {
+			continue
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// writeTagIndex replaces tag index file by keys when they changed from count of read keys, empty index is removed
+func writeTagIndex(path string, keys []string, count int) error {
+	if len(keys) == count {
+		return nil
+	}
+	if len(keys) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	var data strings.Builder
+	for _, key := range keys {
+		data.WriteString(strconv.Quote(key) + "\n")
+	}
+	return writeCacheFile(path, []byte(data.String()))
+}
+
+// keyPath returns path of cache file of key, key is hashed so any key is safe file name
+// and files are spread to depth levels of directories
+func keyPath(dir, key string, depth int) string {
+	hash := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(hash[:])
+
+	if depth == 0 {
+		depth = FILE_SHARD_DEPTH
+	}
+	depth = min(depth, len(name)/2)
+
+	parts := make([]string, 0, depth+2)
+	parts = append(parts, dir)
+	for i := 0; i < depth; i++ {
+		parts = append(parts, name[i*2:i*2+2])
+	}
+	parts = append(parts, name+FILE_EXTENSION)
+	return filepath.Join(parts...)
+}
+
+// walkCacheFiles calls fn for every cache file in dir and its subdirectories, tag index is skipped.
+// Temporary files left by interrupted writes are removed, caller must hold exclusive lock of dir.
+func walkCacheFiles(dir string, fn func(path string) error) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path != dir && errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			if path != dir && entry.Name() == TAGS_DIR {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(entry.Name(), FILE_TEMP_EXTENSION) {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			return nil
+		}
+		if !strings.HasSuffix(entry.Name(), FILE_EXTENSION) {
+			return nil
+		}
+		return fn(path)
+	})
+}
+
+// clearDir removes cache files and tag index from dir
+func clearDir(dir string) error {
+	err := walkCacheFiles(dir, func(path string) error {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(filepath.Join(dir, TAGS_DIR))
+}
+
+// createDir create cache directory if it doesn't exist
+func createDir(dir string) error {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return os.MkdirAll(dir, 0755)
+	}
+	return nil
+}
+
+// pingDir checks that dir is writable by creating temporary file
+func pingDir(dir string) error {
+	f, err := os.CreateTemp(dir, "ping*"+FILE_TEMP_EXTENSION)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrBackendUnavailable, err)
+	}
+	_ = f.Close()
+	return os.Remove(f.Name())
+}
+
+// purgeDir removes expired and corrupted cache files from dir
+func purgeDir(dir string, codec Codec, log *cacheLog) (int, error) {
+	now := time.Now()
+	purged := 0
+	err := walkCacheFiles(dir, func(filePath string) error {
+		data, err := os.ReadFile(filePath)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		item, err := decodeFileItem(data, codec)
+		if err != nil {
+			log.corrupt("Purge", "", fmt.Errorf("%s: %w", filePath, err))
+		} else if !item.isExpired(now) {
+			return nil
+		}
+
+		if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		purged++
+		return nil
+	})
+	return purged, err
 }
@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"github.com/gouef/standards"
+)
+
+func (c *File) GetItemContext(ctx context.Context, key string) standards.CacheItem {
+	if ctx.Err() != nil {
+		return nil
+	}
+	return c.GetItem(key)
+}
+
+func (c *File) GetItemsContext(ctx context.Context, keys ...string) []standards.CacheItem {
+	var items []standards.CacheItem
+	for _, key := range keys {
+		if ctx.Err() != nil {
+			return nil
+		}
+		item := c.GetItem(key)
+		if item != nil {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (c *File) HasItemContext(ctx context.Context, key string) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	return c.HasItem(key)
+}
+
+func (c *File) ClearContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Clear()
+}
+
+func (c *File) DeleteItemContext(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.DeleteItem(key)
+}
+
+func (c *File) DeleteItemsContext(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		_ = c.DeleteItem(key)
+	}
+	return nil
+}
+
+func (c *File) SaveContext(ctx context.Context, item standards.CacheItem) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Save(item)
+}
+
+func (c *File) SaveDeferredContext(ctx context.Context, item standards.CacheItem) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.SaveDeferred(item)
+}
+
+func (c *File) CommitContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Commit()
+}
+
+// PingContext checks that cache directory is writable
+func (c *File) PingContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return pingDir(c.Dir)
+}
@@ -1,7 +1,10 @@
 package cache
 
 import (
+	"encoding/json"
+	"errors"
 	"github.com/gouef/standards"
+	"slices"
 	"time"
 )
 
@@ -10,6 +13,25 @@ type FileItem struct {
 	Value      any       `json:"value"`
 	Expiration time.Time `json:"expiration"`
 	KeepTTL    bool
+	Tags       []string `json:"tags,omitempty"`
+	// ComputeTime is time which computation of value took
+	ComputeTime time.Duration `json:"compute_time,omitempty"`
+	// Idle is idle timeout of sliding expiration, MaxExpiration caps sliding
+	Idle          time.Duration `json:"idle,omitempty"`
+	MaxExpiration time.Time     `json:"max_expiration"`
+}
+
+// fileEntry is stored form of FileItem when value is encoded by Codec
+type fileEntry struct {
+	Key        string    `json:"key"`
+	Data       []byte    `json:"data"`
+	Expiration time.Time `json:"expiration"`
+	KeepTTL    bool
+	Tags       []string `json:"tags,omitempty"`
+	// ComputeTime is time which computation of value took
+	ComputeTime   time.Duration `json:"compute_time,omitempty"`
+	Idle          time.Duration `json:"idle,omitempty"`
+	MaxExpiration time.Time     `json:"max_expiration"`
 }
 
 func NewFileItem(key string) *FileItem {
@@ -53,3 +75,121 @@ func (i *FileItem) ExpiresAfter(t time.Duration) {
 		i.ExpiresAt(time.Now().Add(t))
 	}
 }
+
+// ExpiresAfterIdle sets idle timeout of item, every hit pushes expiration forward by idle until maxLifetime
+func (i *FileItem) ExpiresAfterIdle(idle, maxLifetime time.Duration) standards.CacheItem {
+	now := time.Now()
+	i.Idle = max(idle, 0)
+	i.MaxExpiration = maxExpirationAfter(maxLifetime, now)
+	if hasIdleExpiration(i.Idle, i.MaxExpiration) {
+		i.ExpiresAt(idleExpiration(i.Idle, i.MaxExpiration, now))
+	}
+	return i
+}
+
+// GetIdleTimeout returns idle timeout and max expiration of item
+func (i *FileItem) GetIdleTimeout() (time.Duration, time.Time) {
+	return i.Idle, i.MaxExpiration
+}
+
+// slide pushes expiration of item with idle timeout forward, it reports if expiration changed.
+// Expiration moves only by more than Idle/FILE_SLIDE_DIVISOR, so not every hit rewrites the file.
+func (i *FileItem) slide(now time.Time) bool {
+	if i.Idle <= 0 || i.KeepTTL || !i.Expiration.After(now) {
+		return false
+	}
+	expiration := idleExpiration(i.Idle, i.MaxExpiration, now)
+	if expiration.Sub(i.Expiration) <= i.Idle/FILE_SLIDE_DIVISOR {
+		return false
+	}
+	i.Expiration = expiration
+	return true
+}
+
+// SetComputeTime sets time which computation of value took, it is used by XFetch
+func (i *FileItem) SetComputeTime(d time.Duration) {
+	i.ComputeTime = d
+}
+
+// GetComputeTime returns time which computation of value took
+func (i *FileItem) GetComputeTime() time.Duration {
+	return i.ComputeTime
+}
+
+// GetExpiration returns expiration of item, ok is false when item never expires
+func (i *FileItem) GetExpiration() (time.Time, bool) {
+	if i.KeepTTL || i.Expiration.IsZero() {
+		return time.Time{}, false
+	}
+	return i.Expiration, true
+}
+
+// Tag adds tags to item
+func (i *FileItem) Tag(tags ...string) standards.CacheItem {
+	i.Tags = appendTags(i.Tags, tags...)
+	return i
+}
+
+// GetTags returns tags of item
+func (i *FileItem) GetTags() []string {
+	return slices.Clone(i.Tags)
+}
+
+func (i *FileItem) isExpired(now time.Time) bool {
+	return !i.KeepTTL && !i.Expiration.IsZero() && !i.Expiration.After(now)
+}
+
+// encodeFileItem returns file content of item, value is encoded by codec (JSON file when codec is nil)
+func encodeFileItem(item *FileItem, codec Codec) ([]byte, error) {
+	if codec == nil {
+		return json.Marshal(item)
+	}
+
+	data, err := codec.Marshal(item.Value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(fileEntry{
+		Key:           item.Key,
+		Data:          data,
+		Expiration:    item.Expiration,
+		KeepTTL:       item.KeepTTL,
+		Tags:          item.Tags,
+		ComputeTime:   item.ComputeTime,
+		Idle:          item.Idle,
+		MaxExpiration: item.MaxExpiration,
+	})
+}
+
+// decodeFileItem returns item from file content created by encodeFileItem
+func decodeFileItem(data []byte, codec Codec) (*FileItem, error) {
+	if codec == nil {
+		var item *FileItem
+		if err := json.Unmarshal(data, &item); err != nil {
+			return nil, err
+		}
+		if item == nil {
+			return nil, errors.New("empty cache file")
+		}
+		return item, nil
+	}
+
+	var entry fileEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+
+	item := &FileItem{
+		Key:           entry.Key,
+		Expiration:    entry.Expiration,
+		KeepTTL:       entry.KeepTTL,
+		Tags:          entry.Tags,
+		ComputeTime:   entry.ComputeTime,
+		Idle:          entry.Idle,
+		MaxExpiration: entry.MaxExpiration,
+	}
+	if err := codec.Unmarshal(entry.Data, &item.Value); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FILE_LOCK is lock file inside cache directory used for locking between processes
+const FILE_LOCK = ".lock"
+
+// FILE_TEMP_EXTENSION is extension of temporary files written before rename to cache file
+const FILE_TEMP_EXTENSION = ".tmp"
+
+// dirLock is advisory lock of cache directory shared by all processes, shared lock is used for reads
+// and exclusive lock for writes. Lock file is opened once and shared lock is held while any reader of cache reads.
+// Callers hold exclusive lock of cache for writes, so exclusive lock never meets readers of the same cache.
+type dirLock struct {
+	mu      sync.Mutex
+	file    *os.File
+	readers int
+}
+
+// createLockFile creates lock file of cache directory, so readers don't need write access to directory
+func createLockFile(dir string) {
+	if f, err := os.OpenFile(filepath.Join(dir, FILE_LOCK), os.O_CREATE|os.O_RDONLY, 0644); err == nil {
+		_ = f.Close()
+	}
+}
+
+// lock acquires lock of dir, readers which can't open lock file (e.g. read-only directory) read without lock
+func (l *dirLock) lock(dir string, exclusive bool) (unlock func(), err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		flags := os.O_RDONLY
+		if exclusive {
+			flags |= os.O_CREATE
+		}
+		f, err := os.OpenFile(filepath.Join(dir, FILE_LOCK), flags, 0644)
+		if err != nil {
+			if exclusive {
+				return nil, err
+			}
+			return func() {}, nil
+		}
+		l.file = f
+	}
+
+	if exclusive {
+		if err := flock(l.file, true); err != nil {
+			return nil, err
+		}
+		return func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			_ = funlock(l.file)
+		}, nil
+	}
+
+	if l.readers == 0 {
+		if err := flock(l.file, false); err != nil {
+			return nil, err
+		}
+	}
+	l.readers++
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.readers--
+		if l.readers == 0 {
+			_ = funlock(l.file)
+		}
+	}, nil
+}
+
+// close closes lock file, it is opened again by next lock
+func (l *dirLock) close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil || l.readers > 0 {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// writeCacheFile writes cache file atomically (temporary file, fsync, rename and fsync of directory) and creates its directory,
+// readers never see partially written file
+func writeCacheFile(path string, data []byte) (err error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*"+FILE_TEMP_EXTENSION)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}
+	}()
+
+	if _, err = f.Write(data); err != nil {
+		return err
+	}
+	if err = f.Chmod(0644); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(f.Name(), path); err != nil {
+		return err
+	}
+	syncDir(dir)
+	return nil
+}
+
+// syncDir flushes directory entries of dir, so renamed file survives crash, it is best effort
+// (e.g. directories can't be synced on Windows)
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
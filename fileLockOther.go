@@ -0,0 +1,14 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package cache
+
+import "os"
+
+// flock is not supported on this platform, only locking inside process is used
+func flock(f *os.File, exclusive bool) error {
+	return nil
+}
+
+func funlock(f *os.File) error {
+	return nil
+}
@@ -1,12 +1,11 @@
 package cache
 
 import (
-	"encoding/json"
-	"github.com/gouef/standards"
+	"errors"
+	"fmt"
 	"os"
-	"path/filepath"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -15,6 +14,17 @@ type FileSimple struct {
 	Mu              sync.RWMutex
 	AllowDefaultNil bool
 	KeepTTL         bool
+	// Codec serializes values, nil means whole item is stored as JSON.
+	Codec Codec
+	// ShardDepth is count of directory levels of cache files, 0 means FILE_SHARD_DEPTH, negative means no directories.
+	ShardDepth int
+	// XFetchBeta enables probabilistic early expiration (XFetch) of items with compute time, 0 means disabled.
+	// FileSimple doesn't record compute time, it applies to items saved by File into the same directory.
+	XFetchBeta float64
+	// expirations count removed expired files
+	expirations atomic.Uint64
+	log         cacheLog
+	lock        dirLock
 }
 
 // NewFileSimple create FileSimple instance with not allowed default value nil
@@ -24,40 +34,88 @@ func NewFileSimple(dir string) (*FileSimple, error) {
 
 // NewFileSimpleWithDefaultNil create FileSimple instance
 func NewFileSimpleWithDefaultNil(dir string, allowDefaultNil bool) (*FileSimple, error) {
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return nil, err
-		}
+	c, err := NewFileSimpleWithOptions(dir, FileOptions{})
+	if err != nil {
+		return nil, err
 	}
-	return &FileSimple{
-		Dir:             dir,
-		AllowDefaultNil: allowDefaultNil,
-	}, nil
+	c.AllowDefaultNil = allowDefaultNil
+	return c, nil
+}
+
+// NewFileSimpleWithOptions create FileSimple instance with not allowed default value nil
+func NewFileSimpleWithOptions(dir string, options FileOptions) (*FileSimple, error) {
+	if err := createDir(dir); err != nil {
+		return nil, err
+	}
+	createLockFile(dir)
+	c := &FileSimple{
+		Dir:        dir,
+		Codec:      options.Codec,
+		ShardDepth: options.ShardDepth,
+		XFetchBeta: options.XFetchBeta,
+	}
+	c.log.set(options.Log)
+	return c, nil
+}
+
+// SetLogOptions configure logging of errors, corrupt files and slow operations
+func (c *FileSimple) SetLogOptions(options LogOptions) {
+	c.log.set(options)
 }
 
 // Get Returns a value from the cache.
 func (c *FileSimple) Get(key string, defaultValue any) any {
+	value, err := c.GetE(key)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// GetE returns value of key, error is ErrMiss for missing or expired file, wraps ErrBackendUnavailable
+// when file can't be read and ErrCorruptEntry when file can't be decoded
+func (c *FileSimple) GetE(key string) (any, error) {
+	defer c.log.slow("Get", key, time.Now())
 	c.Mu.RLock()
 	defer c.Mu.RUnlock()
 
+	unlock, err := c.lock.lock(c.Dir, false)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBackendUnavailable, c.log.failed("Get", key, err))
+	}
+	defer unlock()
+
 	filePath := c.getFilePath(key)
 	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return nil, ErrMiss
+	}
 	if err != nil {
-		return defaultValue
+		return nil, fmt.Errorf("%w: %w", ErrBackendUnavailable, c.log.failed("Get", key, err))
 	}
 
-	var item *FileItem
-	if err := json.Unmarshal(data, &item); err != nil {
+	item, err := decodeFileItem(data, c.Codec)
+	if err != nil {
+		c.log.corrupt("Get", key, err)
 		_ = os.Remove(filePath)
-		return defaultValue
+		return nil, fmt.Errorf("%w: %w", ErrCorruptEntry, err)
 	}
 
 	if time.Now().After(item.Expiration) && !item.Expiration.IsZero() {
 		_ = os.Remove(filePath)
-		return defaultValue
+		c.expirations.Add(1)
+		return nil, ErrMiss
+	}
+	now := time.Now()
+	if expiresEarly(item, c.XFetchBeta, now) {
+		return nil, ErrMiss
+	}
+	// items with idle timeout saved by File slide like in File
+	if item.slide(now) {
+		_ = c.log.failed("Get", key, writeSlidItem(filePath, item, c.Codec))
 	}
 
-	return item.Value
+	return item.Value, nil
 }
 
 // GetMultiply Returns a list of cache items.
@@ -86,24 +144,30 @@ func (c *FileSimple) Has(key string) bool {
 	return false
 }
 
+// HasE Determines whether an item is present in the cache, misses are not errors.
+func (c *FileSimple) HasE(key string) (bool, error) {
+	value, err := c.GetE(key)
+	if errors.Is(err, ErrMiss) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return c.AllowDefaultNil || value != nil, nil
+}
+
 // Clear Deletes all cache's keys.
 func (c *FileSimple) Clear() error {
 	c.Mu.Lock()
 	defer c.Mu.Unlock()
 
-	files, err := os.ReadDir(c.Dir)
+	unlock, err := c.lock.lock(c.Dir, true)
 	if err != nil {
-		return err
+		return c.log.failed("Clear", "", err)
 	}
+	defer unlock()
 
-	for _, file := range files {
-		name := strings.Replace(file.Name(), FILE_EXTENSION, "", 1)
-		err := os.Remove(c.getFilePath(name))
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	return c.log.failed("Clear", "", clearDir(c.Dir))
 }
 
 // Delete Remove an item from the cache.
@@ -111,7 +175,17 @@ func (c *FileSimple) Delete(key string) error {
 	c.Mu.Lock()
 	defer c.Mu.Unlock()
 
-	return os.Remove(c.getFilePath(key))
+	unlock, err := c.lock.lock(c.Dir, true)
+	if err != nil {
+		return c.log.failed("Delete", key, err)
+	}
+	defer unlock()
+
+	err = os.Remove(c.getFilePath(key))
+	if os.IsNotExist(err) {
+		return err
+	}
+	return c.log.failed("Delete", key, err)
 }
 
 // DeleteMultiply Removes multiple items in a single operation.
@@ -119,54 +193,112 @@ func (c *FileSimple) DeleteMultiply(keys ...string) error {
 	c.Mu.Lock()
 	defer c.Mu.Unlock()
 
+	unlock, err := c.lock.lock(c.Dir, true)
+	if err != nil {
+		return c.log.failed("DeleteMultiply", "", err)
+	}
+	defer unlock()
+
 	for _, key := range keys {
-		_ = os.Remove(c.getFilePath(key))
+		if err := os.Remove(c.getFilePath(key)); err != nil && !os.IsNotExist(err) {
+			_ = c.log.failed("DeleteMultiply", key, err)
+		}
 	}
 	return nil
 }
 
 // Set Persists a cache item.
 func (c *FileSimple) Set(key string, item any, ttl time.Duration) error {
+	defer c.log.slow("Set", key, time.Now())
 	fItem := c.getFileItem(key, item, ttl)
 
-	data, err := json.Marshal(fItem)
+	data, err := encodeFileItem(fItem, c.Codec)
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(c.getFilePath(fItem.GetKey()), data, 0644)
+	c.Mu.Lock()
+	defer c.Mu.Unlock()
+
+	unlock, err := c.lock.lock(c.Dir, true)
+	if err != nil {
+		return c.log.failed("Set", key, err)
+	}
+	defer unlock()
+
+	return c.log.failed("Set", key, writeCacheFile(c.getFilePath(fItem.GetKey()), data))
 }
 
 // SetMultiply Persists a cache items.
 func (c *FileSimple) SetMultiply(values map[string]any, ttl time.Duration) error {
+	c.Mu.Lock()
+	defer c.Mu.Unlock()
+
+	defer c.log.slow("SetMultiply", "", time.Now())
+
+	unlock, err := c.lock.lock(c.Dir, true)
+	if err != nil {
+		return c.log.failed("SetMultiply", "", err)
+	}
+	defer unlock()
+
 	for key, value := range values {
 		item := c.getFileItem(key, value, ttl)
 		item.ExpiresAfter(ttl)
 
-		data, err := json.Marshal(item)
+		data, err := encodeFileItem(item, c.Codec)
 		if err != nil {
 			return err
 		}
 
-		err = os.WriteFile(c.getFilePath(item.GetKey()), data, 0644)
+		err = writeCacheFile(c.getFilePath(item.GetKey()), data)
 
 		if err != nil {
-			return err
+			return c.log.failed("SetMultiply", key, err)
 		}
 	}
 
 	return nil
 }
 
-func (c *FileSimple) getFileItem(key string, value any, ttl time.Duration) standards.CacheItem {
+// Purge Removes expired and corrupted cache files.
+func (c *FileSimple) Purge() (int, error) {
 	c.Mu.Lock()
 	defer c.Mu.Unlock()
 
-	item, _ := NewFileItem(key).Set(value, ttl)
+	unlock, err := c.lock.lock(c.Dir, true)
+	if err != nil {
+		return 0, c.log.failed("Purge", "", err)
+	}
+	defer unlock()
+
+	purged, err := purgeDir(c.Dir, c.Codec, &c.log)
+	c.expirations.Add(uint64(purged))
+	return purged, c.log.failed("Purge", "", err)
+}
+
+// Close closes lock file of cache directory, cache can be used again
+func (c *FileSimple) Close() error {
+	return c.lock.close()
+}
+
+// Expirations returns count of expired files removed by Get and Purge (Purge counts corrupted files too)
+func (c *FileSimple) Expirations() uint64 {
+	return c.expirations.Load()
+}
+
+func (c *FileSimple) getFileItem(key string, value any, ttl time.Duration) *FileItem {
+	item := NewFileItem(key)
+	_, _ = item.Set(value, ttl)
 
 	return item
 }
 
+// FilePath returns path of cache file of key
+func (c *FileSimple) FilePath(key string) string {
+	return c.getFilePath(key)
+}
+
 func (c *FileSimple) getFilePath(key string) string {
-	return filepath.Join(c.Dir, key+FILE_EXTENSION)
+	return keyPath(c.Dir, key, c.ShardDepth)
 }
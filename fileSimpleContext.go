@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// GetContext Returns a value from the cache, defaultValue is returned when context is done.
+func (c *FileSimple) GetContext(ctx context.Context, key string, defaultValue any) any {
+	if ctx.Err() != nil {
+		return defaultValue
+	}
+	return c.Get(key, defaultValue)
+}
+
+// GetMultiplyContext Returns a list of cache items.
+func (c *FileSimple) GetMultiplyContext(ctx context.Context, keys []string, defaultValue any) []any {
+	result := []any{}
+
+	for _, key := range keys {
+		if ctx.Err() != nil {
+			return result
+		}
+		item := c.Get(key, defaultValue)
+
+		if (c.AllowDefaultNil && item == nil) || item != nil {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
+
+// HasContext Determines whether an item is present in the cache.
+func (c *FileSimple) HasContext(ctx context.Context, key string) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	return c.Has(key)
+}
+
+// ClearContext Deletes all cache's keys.
+func (c *FileSimple) ClearContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Clear()
+}
+
+// DeleteContext Remove an item from the cache.
+func (c *FileSimple) DeleteContext(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Delete(key)
+}
+
+// DeleteMultiplyContext Removes multiple items in a single operation.
+func (c *FileSimple) DeleteMultiplyContext(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		_ = c.Delete(key)
+	}
+	return nil
+}
+
+// SetContext Persists a cache item.
+func (c *FileSimple) SetContext(ctx context.Context, key string, item any, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Set(key, item, ttl)
+}
+
+// SetMultiplyContext Persists a cache items.
+func (c *FileSimple) SetMultiplyContext(ctx context.Context, values map[string]any, ttl time.Duration) error {
+	for key, value := range values {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := c.SetMultiply(map[string]any{key: value}, ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PingContext checks that cache directory is writable
+func (c *FileSimple) PingContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return pingDir(c.Dir)
+}
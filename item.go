@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"errors"
+	"github.com/gouef/standards"
+	"time"
+)
+
+// ItemFactory is cache which can create empty item of its own type.
+type ItemFactory interface {
+	NewItem(key string) standards.CacheItem
+}
+
+// ExpiringItem is item which exposes its expiration.
+type ExpiringItem interface {
+	// GetExpiration returns expiration of item, ok is false when item never expires.
+	GetExpiration() (expiration time.Time, ok bool)
+}
+
+// newCacheItem create item for cache with value and ttl, zero ttl means item never expires
+func newCacheItem(c standards.Cache, key string, value any, ttl time.Duration) (standards.CacheItem, error) {
+	factory, ok := c.(ItemFactory)
+	if !ok {
+		return nil, errors.New("cache does not implement ItemFactory")
+	}
+
+	if ttl <= 0 {
+		ttl = KeepTTL
+	}
+
+	item, err := factory.NewItem(key).Set(value, ttl)
+	if err != nil {
+		return nil, err
+	}
+	if ttl != KeepTTL {
+		item.ExpiresAfter(ttl)
+	}
+	return item, nil
+}
+
+// remainingTTL returns time until item expires, zero ttl means item never expires (or expiration is unknown)
+// and expired is true when item already expired
+func remainingTTL(item standards.CacheItem) (ttl time.Duration, expired bool) {
+	expiring, ok := item.(ExpiringItem)
+	if !ok {
+		return 0, false
+	}
+	expiration, ok := expiring.GetExpiration()
+	if !ok {
+		return 0, false
+	}
+	ttl = time.Until(expiration)
+	return ttl, ttl <= 0
+}
+
+// copyItem create item of cache with key and value, tags and ttl of item
+func copyItem(c standards.Cache, key string, item standards.CacheItem, ttl time.Duration) (standards.CacheItem, error) {
+	copied, err := newCacheItem(c, key, item.Get(), ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	setComputeTime(copied, computeTimeOf(item))
+	if idle, _ := idleTimeoutOf(item); idle > 0 {
+		copyIdleTimeout(copied, item)
+		// ttl of copy stays capped (e.g. by BackfillTTL of Chain)
+		if ttl > 0 {
+			copied.ExpiresAfter(ttl)
+		}
+	}
+
+	tagged, ok := item.(TaggedItem)
+	if !ok {
+		return copied, nil
+	}
+	if tags := tagged.GetTags(); len(tags) > 0 {
+		if taggedCopy, ok := copied.(TaggedItem); ok {
+			taggedCopy.Tag(tags...)
+		}
+	}
+	return copied, nil
+}
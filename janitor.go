@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Purger is cache which can remove its expired entries.
+type Purger interface {
+	// Purge removes expired entries and returns how many were removed.
+	Purge() (int, error)
+}
+
+// Janitor periodically purges expired entries of cache in background.
+type Janitor struct {
+	purger   Purger
+	interval time.Duration
+	onPurge  func(purged int, err error)
+	purged   atomic.Uint64
+	cancel   context.CancelFunc
+	done     chan struct{}
+	once     sync.Once
+}
+
+// StartJanitor start background purging of cache every interval until ctx is done or Close is called.
+// onPurge is optional and is called after every run.
+func StartJanitor(ctx context.Context, purger Purger, interval time.Duration, onPurge func(purged int, err error)) *Janitor {
+	ctx, cancel := context.WithCancel(ctx)
+	j := &Janitor{
+		purger:   purger,
+		interval: interval,
+		onPurge:  onPurge,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	go j.run(ctx)
+	return j
+}
+
+// Purged returns total count of entries purged by Janitor
+func (j *Janitor) Purged() uint64 {
+	return j.purged.Load()
+}
+
+// Close stop Janitor and wait until running purge is finished
+func (j *Janitor) Close() error {
+	j.once.Do(j.cancel)
+	<-j.done
+	return nil
+}
+
+func (j *Janitor) run(ctx context.Context) {
+	defer close(j.done)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := j.purger.Purge()
+			j.purged.Add(uint64(purged))
+			if j.onPurge != nil {
+				j.onPurge(purged, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"github.com/gouef/standards"
+	"sync"
+	"time"
+)
+
+// Loader loads missing values into cache. Concurrent misses of the same key are loaded only once per process.
+type Loader struct {
+	cache standards.Cache
+	// ErrorTTL caches loader errors for given time, 0 means errors are not cached.
+	ErrorTTL time.Duration
+	mu       sync.Mutex
+	errors   map[string]loaderError
+}
+
+type loaderError struct {
+	err   error
+	until time.Time
+}
+
+// NewLoader create Loader instance for cache
+func NewLoader(cache standards.Cache) *Loader {
+	return &Loader{
+		cache:  cache,
+		errors: make(map[string]loaderError),
+	}
+}
+
+// GetOrLoad returns value of key from cache, missing value is loaded by loader and stored with ttl.
+// Loaded value is returned even when it can't be stored, together with the error.
+func GetOrLoad(c standards.Cache, key string, ttl time.Duration, loader func() (any, error)) (any, error) {
+	if value, found := getHit(c, key); found {
+		return value, nil
+	}
+
+	return flights.Do(c, key, func() (any, error) {
+		return load(c, key, ttl, loader)
+	})
+}
+
+// GetOrLoad returns value of key from cache, missing value is loaded by loader and stored with ttl.
+// Cached error is returned only when value is missing, loaded value removes cached error.
+func (l *Loader) GetOrLoad(key string, ttl time.Duration, loader func() (any, error)) (any, error) {
+	if value, found := getHit(l.cache, key); found {
+		return value, nil
+	}
+	if err := l.cachedError(key); err != nil {
+		return nil, err
+	}
+
+	return flights.Do(l.cache, key, func() (any, error) {
+		return load(l.cache, key, ttl, func() (any, error) {
+			value, err := loader()
+			if err != nil {
+				l.cacheError(key, err)
+			} else {
+				l.Forget(key)
+			}
+			return value, err
+		})
+	})
+}
+
+// Forget removes cached error of key
+func (l *Loader) Forget(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.errors, key)
+}
+
+func (l *Loader) cachedError(key string) error {
+	if l.ErrorTTL <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cached, exists := l.errors[key]
+	if !exists {
+		return nil
+	}
+	if time.Now().After(cached.until) {
+		delete(l.errors, key)
+		return nil
+	}
+	return cached.err
+}
+
+func (l *Loader) cacheError(key string, err error) {
+	if l.ErrorTTL <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	for k, cached := range l.errors {
+		if now.After(cached.until) {
+			delete(l.errors, k)
+		}
+	}
+	l.errors[key] = loaderError{err: err, until: now.Add(l.ErrorTTL)}
+}
+
+// getHit returns value of key when it is in cache and not expired
+func getHit(c standards.Cache, key string) (any, bool) {
+	item := c.GetItem(key)
+	if item == nil || !item.IsHit() {
+		return nil, false
+	}
+	return item.Get(), true
+}
+
+func load(c standards.Cache, key string, ttl time.Duration, loader func() (any, error)) (any, error) {
+	start := time.Now()
+	value, err := loader()
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := newCacheItem(c, key, value, ttl)
+	if err != nil {
+		return value, err
+	}
+	// compute time is used by XFetch
+	setComputeTime(item, time.Since(start))
+	return value, c.Save(item)
+}
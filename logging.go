@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"context"
+	"github.com/gouef/standards"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// LogOptions configure logging of cache.
+type LogOptions struct {
+	// Logger receives logs, nil means logging is disabled.
+	Logger *slog.Logger
+	// Name is logged as "cache" attribute, Storage sets name of cache.
+	Name string
+	// ErrorLevel is level of backend errors, nil means slog.LevelError.
+	ErrorLevel slog.Leveler
+	// CorruptLevel is level of removed or skipped corrupt entries, nil means slog.LevelWarn.
+	CorruptLevel slog.Leveler
+	// SlowLevel is level of slow operations, nil means slog.LevelWarn.
+	SlowLevel slog.Leveler
+	// SlowThreshold is duration from which operation is logged as slow, 0 means disabled.
+	SlowThreshold time.Duration
+}
+
+// LogConfigurable is cache with configurable logging.
+type LogConfigurable interface {
+	SetLogOptions(options LogOptions)
+}
+
+// LoggingMiddleware logs every operation to logger, successful operations with debug level and failures with error level
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	return LoggingMiddlewareWithOptions(LogOptions{Logger: logger})
+}
+
+// LoggingMiddlewareWithOptions logs every operation, successful operations with debug level,
+// failures with ErrorLevel and operations longer than SlowThreshold with SlowLevel
+func LoggingMiddlewareWithOptions(options LogOptions) Middleware {
+	return func(cache standards.Cache) standards.Cache {
+		return NewObserveCache(cache, func(op Operation) {
+			logOperation(options, op)
+		})
+	}
+}
+
+func logOperation(options LogOptions, op Operation) {
+	if options.Logger == nil {
+		return
+	}
+
+	level := slog.LevelDebug
+	attrs := []slog.Attr{
+		slog.Any("keys", op.Keys),
+		slog.Int("hits", op.Hits),
+		slog.Duration("duration", op.Duration),
+	}
+	if options.Name != "" {
+		attrs = append(attrs, slog.String("cache", options.Name))
+	}
+	if options.SlowThreshold > 0 && op.Duration >= options.SlowThreshold {
+		level = levelOf(options.SlowLevel, slog.LevelWarn)
+	}
+	if op.Err != nil {
+		level = levelOf(options.ErrorLevel, slog.LevelError)
+		attrs = append(attrs, slog.Any("error", op.Err))
+	}
+	options.Logger.LogAttrs(context.Background(), level, "cache "+op.Name, attrs...)
+}
+
+func levelOf(leveler slog.Leveler, fallback slog.Level) slog.Level {
+	if leveler == nil {
+		return fallback
+	}
+	return leveler.Level()
+}
+
+// cacheLog logs errors, corrupt entries and slow operations of backend
+type cacheLog struct {
+	options atomic.Pointer[LogOptions]
+}
+
+func (l *cacheLog) set(options LogOptions) {
+	l.options.Store(&options)
+}
+
+// failed logs err of operation and returns it
+func (l *cacheLog) failed(op, key string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if options := l.options.Load(); options != nil {
+		l.log(options, levelOf(options.ErrorLevel, slog.LevelError), "cache operation failed", op, key,
+			slog.Any("error", err))
+	}
+	return err
+}
+
+// corrupt logs corrupt entry of key
+func (l *cacheLog) corrupt(op, key string, err error) {
+	if options := l.options.Load(); options != nil {
+		l.log(options, levelOf(options.CorruptLevel, slog.LevelWarn), "cache entry is corrupt", op, key,
+			slog.Any("error", err))
+	}
+}
+
+// slow logs operation started at start when it took at least SlowThreshold, use it with defer
+func (l *cacheLog) slow(op, key string, start time.Time) {
+	options := l.options.Load()
+	if options == nil || options.SlowThreshold <= 0 {
+		return
+	}
+	if duration := time.Since(start); duration >= options.SlowThreshold {
+		l.log(options, levelOf(options.SlowLevel, slog.LevelWarn), "cache operation is slow", op, key,
+			slog.Duration("duration", duration))
+	}
+}
+
+func (l *cacheLog) log(options *LogOptions, level slog.Level, msg, op, key string, attrs ...slog.Attr) {
+	if options.Logger == nil {
+		return
+	}
+
+	attrs = append(attrs, slog.String("operation", op))
+	if key != "" {
+		attrs = append(attrs, slog.String("key", key))
+	}
+	if options.Name != "" {
+		attrs = append(attrs, slog.String("cache", options.Name))
+	}
+	options.Logger.LogAttrs(context.Background(), level, msg, attrs...)
+}
@@ -1,64 +1,196 @@
 package cache
 
 import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/gouef/standards"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type Memory struct {
-	items map[string]*MemoryItem
-	mu    sync.RWMutex
+	items    map[string]*MemoryItem
+	deferred map[string]*MemoryItem
+	sizes    map[string]int64
+	bytes    int64
+	options  MemoryOptions
+	janitor  *Janitor
+	mu       sync.RWMutex
+	// evictions and expirations count removed items
+	evictions   atomic.Uint64
+	expirations atomic.Uint64
+	log         cacheLog
+}
+
+// MemoryOptions configure limits of Memory cache.
+type MemoryOptions struct {
+	// MaxEntries is maximum count of stored items, 0 means unlimited.
+	MaxEntries int
+	// MaxBytes is maximum estimated size of stored values, 0 means unlimited.
+	MaxBytes int64
+	// Policy selects items which are evicted when limit is reached, LRU is used when nil.
+	Policy EvictionPolicy
+	// Sizer estimates size of value for MaxBytes limit.
+	Sizer func(value any) int64
+	// OnEvict is called for every item evicted because of limits.
+	OnEvict func(key string, value any)
+	// CleanupInterval starts background purging of expired items, 0 means disabled.
+	CleanupInterval time.Duration
+	// OnPurge is called after every background purge.
+	OnPurge func(purged int, err error)
+	// Log configures logging of slow operations.
+	Log LogOptions
+	// XFetchBeta enables probabilistic early expiration (XFetch) of items with compute time, 0 means disabled.
+	XFetchBeta float64
+}
+
+type evictedItem struct {
+	key   string
+	value any
 }
 
 func NewMemory() *Memory {
-	return &Memory{
-		items: make(map[string]*MemoryItem),
+	return NewMemoryWithOptions(MemoryOptions{})
+}
+
+// NewMemoryWithOptions create new instance of Memory with limits and eviction policy
+func NewMemoryWithOptions(options MemoryOptions) *Memory {
+	if options.Policy == nil && (options.MaxEntries > 0 || options.MaxBytes > 0) {
+		options.Policy = NewLRUPolicy()
+	}
+	if options.Sizer == nil {
+		options.Sizer = sizeOf
+	}
+	c := &Memory{
+		items:    make(map[string]*MemoryItem),
+		deferred: make(map[string]*MemoryItem),
+		sizes:    make(map[string]int64),
+		options:  options,
 	}
+	c.log.set(options.Log)
+	if options.CleanupInterval > 0 {
+		c.janitor = StartJanitor(context.Background(), c, options.CleanupInterval, options.OnPurge)
+	}
+	return c
+}
+
+// SetLogOptions configure logging of slow operations
+func (c *Memory) SetLogOptions(options LogOptions) {
+	c.log.set(options)
+}
+
+// NewItem create empty item for Memory cache
+func (c *Memory) NewItem(key string) standards.CacheItem {
+	return NewMemoryItem(key)
 }
 
 func (c *Memory) GetItem(key string) standards.CacheItem {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	item, exists := c.items[key]
-	if !exists {
+	item := c.get(key)
+	if item == nil {
 		return nil
 	}
 	return item
 }
 
+// GetItemE returns item of key, error is ErrMiss for missing or expired item
+func (c *Memory) GetItemE(key string) (standards.CacheItem, error) {
+	item := c.get(key)
+	if item == nil {
+		return nil, ErrMiss
+	}
+	return hitOrMiss(item)
+}
+
+func (c *Memory) get(key string) *MemoryItem {
+	defer c.log.slow("GetItem", key, time.Now())
+	if c.options.Policy != nil {
+		// policy changes its state on every access
+		c.mu.Lock()
+		defer c.mu.Unlock()
+	} else {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+	}
+
+	if item, exists := c.deferred[key]; exists {
+		return item
+	}
+
+	item := c.getItem(key)
+	if item == nil {
+		return nil
+	}
+	now := time.Now()
+	if expiresEarly(item, c.options.XFetchBeta, now) {
+		return nil
+	}
+	item.slide(now)
+	return item
+}
+
 func (c *Memory) GetItems(keys ...string) []standards.CacheItem {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
 	var result []standards.CacheItem
 	for _, key := range keys {
 		item := c.GetItem(key)
 		if item != nil {
-			result = append(result, c.GetItem(key))
+			result = append(result, item)
 		}
 	}
 	return result
 }
 
 func (c *Memory) HasItem(key string) bool {
-	item := c.GetItem(key)
-	if item == nil {
-		return false
-	}
-	return item.IsHit()
+	has, _ := c.HasItemE(key)
+	return has
+}
+
+// HasItemE reports if key is in cache, Memory never returns error
+func (c *Memory) HasItemE(key string) (bool, error) {
+	return hasItem(c.GetItemE(key))
 }
 
 func (c *Memory) Clear() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	if c.options.Policy != nil {
+		for key := range c.items {
+			c.options.Policy.Remove(key)
+		}
+	}
 	c.items = make(map[string]*MemoryItem)
+	c.deferred = make(map[string]*MemoryItem)
+	c.sizes = make(map[string]int64)
+	c.bytes = 0
+	return nil
+}
+
+// ClearPrefix removes items with keys starting with prefix
+func (c *Memory) ClearPrefix(prefix string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			c.remove(key)
+		}
+	}
+	for key := range c.deferred {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.deferred, key)
+		}
+	}
 	return nil
 }
 
 func (c *Memory) DeleteItem(key string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	delete(c.items, key)
+	c.remove(key)
+	delete(c.deferred, key)
 	return nil
 }
 
@@ -66,26 +198,233 @@ func (c *Memory) DeleteItems(keys ...string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	for _, key := range keys {
-		delete(c.items, key)
+		c.remove(key)
+		delete(c.deferred, key)
 	}
 	return nil
 }
 
 func (c *Memory) Save(item standards.CacheItem) error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
 	mItem, ok := item.(*MemoryItem)
 	if !ok {
 		return errors.New("invalid cache item type")
 	}
-	c.items[mItem.GetKey()] = mItem
+
+	defer c.log.slow("Save", mItem.GetKey(), time.Now())
+
+	c.mu.Lock()
+	delete(c.deferred, mItem.GetKey())
+	evicted := c.set(mItem)
+	c.mu.Unlock()
+
+	c.notifyEvicted(evicted)
 	return nil
 }
 
+// SaveDeferred keeps item pending until Commit, pending item is returned by GetItem
 func (c *Memory) SaveDeferred(item standards.CacheItem) error {
-	return c.Save(item)
+	mItem, ok := item.(*MemoryItem)
+	if !ok {
+		return errors.New("invalid cache item type")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deferred[mItem.GetKey()] = mItem
+	return nil
 }
 
+// Commit saves all pending items at once
 func (c *Memory) Commit() error {
+	defer c.log.slow("Commit", "", time.Now())
+	c.mu.Lock()
+	deferred := c.deferred
+	c.deferred = make(map[string]*MemoryItem)
+	var evicted []evictedItem
+	for _, item := range deferred {
+		evicted = append(evicted, c.set(item)...)
+	}
+	c.mu.Unlock()
+
+	c.notifyEvicted(evicted)
+	return nil
+}
+
+// Discard drops all pending items
+func (c *Memory) Discard() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deferred = make(map[string]*MemoryItem)
 	return nil
 }
+
+// InvalidateTags removes all items with at least one of tags
+func (c *Memory) InvalidateTags(tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, item := range c.items {
+		if hasAnyTag(item.GetTags(), tags) {
+			c.remove(key)
+		}
+	}
+	for key, item := range c.deferred {
+		if hasAnyTag(item.GetTags(), tags) {
+			delete(c.deferred, key)
+		}
+	}
+	return nil
+}
+
+// Purge removes expired items
+func (c *Memory) Purge() (int, error) {
+	defer c.log.slow("Purge", "", time.Now())
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	purged := 0
+	for key, item := range c.items {
+		if item.isExpired(now) {
+			c.remove(key)
+			purged++
+		}
+	}
+	c.expirations.Add(uint64(purged))
+	return purged, nil
+}
+
+// Close stop background purging
+func (c *Memory) Close() error {
+	if c.janitor == nil {
+		return nil
+	}
+	return c.janitor.Close()
+}
+
+// Evictions returns count of items evicted because of limits
+func (c *Memory) Evictions() uint64 {
+	return c.evictions.Load()
+}
+
+// Expirations returns count of expired items removed by Purge
+func (c *Memory) Expirations() uint64 {
+	return c.expirations.Load()
+}
+
+// Len returns count of stored items
+func (c *Memory) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.items)
+}
+
+// Size returns estimated size of stored values, it is tracked only when MaxBytes is set
+func (c *Memory) Size() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.bytes
+}
+
+func (c *Memory) getItem(key string) *MemoryItem {
+	item, exists := c.items[key]
+	if !exists {
+		return nil
+	}
+	if c.options.Policy != nil {
+		c.options.Policy.Access(key)
+	}
+	return item
+}
+
+func (c *Memory) set(item *MemoryItem) []evictedItem {
+	key := item.GetKey()
+	_, exists := c.items[key]
+	c.items[key] = item
+
+	if c.options.MaxBytes > 0 {
+		size := c.options.Sizer(item.getValue())
+		c.bytes += size - c.sizes[key]
+		c.sizes[key] = size
+	}
+
+	if c.options.Policy == nil {
+		return nil
+	}
+	if exists {
+		c.options.Policy.Access(key)
+		return c.evict()
+	}
+
+	// new key is registered after making room, so it is not chosen as victim
+	// before older items (e.g. LFU would always evict the newest key)
+	evicted := c.evict()
+	c.options.Policy.Add(key)
+	return append(evicted, c.evict()...)
+}
+
+func (c *Memory) remove(key string) {
+	if _, exists := c.items[key]; !exists {
+		return
+	}
+	delete(c.items, key)
+	c.bytes -= c.sizes[key]
+	delete(c.sizes, key)
+	if c.options.Policy != nil {
+		c.options.Policy.Remove(key)
+	}
+}
+
+func (c *Memory) evict() []evictedItem {
+	var evicted []evictedItem
+	for c.overLimit() {
+		key, ok := c.options.Policy.Victim()
+		if !ok {
+			break
+		}
+		item, exists := c.items[key]
+		if !exists {
+			// policy is out of sync, forget the key
+			c.options.Policy.Remove(key)
+			continue
+		}
+		c.remove(key)
+		evicted = append(evicted, evictedItem{key: key, value: item.getValue()})
+	}
+	c.evictions.Add(uint64(len(evicted)))
+	return evicted
+}
+
+func (c *Memory) overLimit() bool {
+	return (c.options.MaxEntries > 0 && len(c.items) > c.options.MaxEntries) ||
+		(c.options.MaxBytes > 0 && c.bytes > c.options.MaxBytes)
+}
+
+func (c *Memory) notifyEvicted(evicted []evictedItem) {
+	if c.options.OnEvict == nil {
+		return
+	}
+	for _, e := range evicted {
+		c.options.OnEvict(e.key, e.value)
+	}
+}
+
+// sizeOf estimates size of value in bytes
+func sizeOf(value any) int64 {
+	switch v := value.(type) {
+	case nil:
+		return 0
+	case string:
+		return int64(len(v))
+	case []byte:
+		return int64(len(v))
+	}
+
+	if size := binary.Size(value); size >= 0 {
+		return int64(size)
+	}
+	if data, err := json.Marshal(value); err == nil {
+		return int64(len(data))
+	}
+	return int64(len(fmt.Sprint(value)))
+}
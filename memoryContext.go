@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"github.com/gouef/standards"
+)
+
+func (c *Memory) GetItemContext(ctx context.Context, key string) standards.CacheItem {
+	if ctx.Err() != nil {
+		return nil
+	}
+	return c.GetItem(key)
+}
+
+func (c *Memory) GetItemsContext(ctx context.Context, keys ...string) []standards.CacheItem {
+	if ctx.Err() != nil {
+		return nil
+	}
+	return c.GetItems(keys...)
+}
+
+func (c *Memory) HasItemContext(ctx context.Context, key string) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	return c.HasItem(key)
+}
+
+func (c *Memory) ClearContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Clear()
+}
+
+func (c *Memory) DeleteItemContext(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.DeleteItem(key)
+}
+
+func (c *Memory) DeleteItemsContext(ctx context.Context, keys ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.DeleteItems(keys...)
+}
+
+func (c *Memory) SaveContext(ctx context.Context, item standards.CacheItem) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Save(item)
+}
+
+func (c *Memory) SaveDeferredContext(ctx context.Context, item standards.CacheItem) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.SaveDeferred(item)
+}
+
+func (c *Memory) CommitContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Commit()
+}
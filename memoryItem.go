@@ -2,6 +2,7 @@ package cache
 
 import (
 	"github.com/gouef/standards"
+	"slices"
 	"sync"
 	"time"
 )
@@ -12,7 +13,13 @@ type MemoryItem struct {
 	expiration time.Time
 	KeepTTL    bool
 	hit        bool
-	mu         sync.RWMutex
+	tags       []string
+	// computeTime is time which computation of value took
+	computeTime time.Duration
+	// idle is idle timeout of sliding expiration, maxExpiration caps sliding
+	idle          time.Duration
+	maxExpiration time.Time
+	mu            sync.RWMutex
 }
 
 func NewMemoryItem(key string) *MemoryItem {
@@ -42,11 +49,13 @@ func (m *MemoryItem) IsHit() bool {
 	return m.KeepTTL || (m.hit && (m.expiration.IsZero() || m.expiration.After(time.Now())))
 }
 
+// Set sets value of item and marks it as hit, item is hit until ttl expires
 func (m *MemoryItem) Set(value any, ttl time.Duration) (standards.CacheItem, error) {
 
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.value = value
+	m.hit = true
 	m.ExpiresAfter(ttl)
 	return m, nil
 }
@@ -65,3 +74,84 @@ func (m *MemoryItem) ExpiresAfter(t time.Duration) {
 		m.ExpiresAt(time.Now().Add(t))
 	}
 }
+
+func (m *MemoryItem) getValue() any {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.value
+}
+
+func (m *MemoryItem) isExpired(now time.Time) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return !m.KeepTTL && !m.expiration.IsZero() && !m.expiration.After(now)
+}
+
+// ExpiresAfterIdle sets idle timeout of item, every hit pushes expiration forward by idle until maxLifetime
+func (m *MemoryItem) ExpiresAfterIdle(idle, maxLifetime time.Duration) standards.CacheItem {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	m.idle = max(idle, 0)
+	m.maxExpiration = maxExpirationAfter(maxLifetime, now)
+	if hasIdleExpiration(m.idle, m.maxExpiration) {
+		m.ExpiresAt(idleExpiration(m.idle, m.maxExpiration, now))
+	}
+	return m
+}
+
+// GetIdleTimeout returns idle timeout and max expiration of item
+func (m *MemoryItem) GetIdleTimeout() (time.Duration, time.Time) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.idle, m.maxExpiration
+}
+
+// slide pushes expiration of item with idle timeout forward, expired item isn't revived
+func (m *MemoryItem) slide(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.idle <= 0 || m.KeepTTL || !m.hit || !m.expiration.After(now) {
+		return
+	}
+	m.expiration = idleExpiration(m.idle, m.maxExpiration, now)
+}
+
+// SetComputeTime sets time which computation of value took, it is used by XFetch
+func (m *MemoryItem) SetComputeTime(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.computeTime = d
+}
+
+// GetComputeTime returns time which computation of value took
+func (m *MemoryItem) GetComputeTime() time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.computeTime
+}
+
+// GetExpiration returns expiration of item, ok is false when item never expires
+func (m *MemoryItem) GetExpiration() (time.Time, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.KeepTTL || m.expiration.IsZero() {
+		return time.Time{}, false
+	}
+	return m.expiration, true
+}
+
+// Tag adds tags to item
+func (m *MemoryItem) Tag(tags ...string) standards.CacheItem {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tags = appendTags(m.tags, tags...)
+	return m
+}
+
+// GetTags returns tags of item
+func (m *MemoryItem) GetTags() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return slices.Clone(m.tags)
+}
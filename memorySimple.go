@@ -0,0 +1,38 @@
+package cache
+
+// MemorySimple is in-memory cache with simple key/value API of FileSimple.
+type MemorySimple struct {
+	*Simple
+	memory *Memory
+}
+
+// NewMemorySimple create MemorySimple instance with not allowed default value nil
+func NewMemorySimple() *MemorySimple {
+	return NewMemorySimpleWithOptions(MemoryOptions{})
+}
+
+// NewMemorySimpleWithDefaultNil create MemorySimple instance
+func NewMemorySimpleWithDefaultNil(allowDefaultNil bool) *MemorySimple {
+	c := NewMemorySimpleWithOptions(MemoryOptions{})
+	c.AllowDefaultNil = allowDefaultNil
+	return c
+}
+
+// NewMemorySimpleWithOptions create MemorySimple instance with limits and eviction policy
+func NewMemorySimpleWithOptions(options MemoryOptions) *MemorySimple {
+	memory := NewMemoryWithOptions(options)
+	return &MemorySimple{
+		Simple: NewSimple(memory),
+		memory: memory,
+	}
+}
+
+// Memory returns underlying Memory cache
+func (c *MemorySimple) Memory() *Memory {
+	return c.memory
+}
+
+// Close stop background purging
+func (c *MemorySimple) Close() error {
+	return c.memory.Close()
+}
@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"bufio"
+	"expvar"
+	"fmt"
+	"github.com/gouef/standards"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// METRICS_PREFIX is prefix of names of exported metrics
+const METRICS_PREFIX = "cache_"
+
+// metricsContentType is content type of Prometheus text exposition format
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// BackendName returns lower-case type name of innermost cache (e.g. "memory", "file", "redis")
+func BackendName(cache standards.Cache) string {
+	t := reflect.TypeOf(UnwrapCache(cache))
+	if t == nil {
+		return ""
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return strings.ToLower(t.Name())
+}
+
+// MetricsHandler returns http.Handler which renders statistics of caches in Prometheus text exposition format,
+// metrics are labelled by cache name and backend type
+func (s *Storage) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", metricsContentType)
+		_ = s.WriteMetrics(w)
+	})
+}
+
+// WriteMetrics writes statistics of caches in Prometheus text exposition format
+func (s *Storage) WriteMetrics(w io.Writer) error {
+	stats := s.Stats()
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	labels := make(map[string]string, len(names))
+	for _, name := range names {
+		cache, _ := s.Get(name)
+		labels[name] = fmt.Sprintf(`cache="%s",backend="%s"`, escapeLabel(name), escapeLabel(BackendName(cache)))
+	}
+
+	b := bufio.NewWriter(w)
+	counters := []struct {
+		name  string
+		help  string
+		value func(Stats) uint64
+	}{
+		{"hits_total", "Count of cache hits.", func(s Stats) uint64 { return s.Hits }},
+		{"misses_total", "Count of cache misses.", func(s Stats) uint64 { return s.Misses }},
+		{"sets_total", "Count of saved items.", func(s Stats) uint64 { return s.Sets }},
+		{"deletes_total", "Count of deleted items.", func(s Stats) uint64 { return s.Deletes }},
+		{"expirations_total", "Count of removed expired items.", func(s Stats) uint64 { return s.Expirations }},
+		{"evictions_total", "Count of items evicted because of limits.", func(s Stats) uint64 { return s.Evictions }},
+	}
+	for _, counter := range counters {
+		fmt.Fprintf(b, "# HELP %s%s %s\n", METRICS_PREFIX, counter.name, counter.help)
+		fmt.Fprintf(b, "# TYPE %s%s counter\n", METRICS_PREFIX, counter.name)
+		for _, name := range names {
+			fmt.Fprintf(b, "%s%s{%s} %d\n", METRICS_PREFIX, counter.name, labels[name], counter.value(stats[name]))
+		}
+	}
+
+	metric := METRICS_PREFIX + "operation_duration_seconds"
+	fmt.Fprintf(b, "# HELP %s Latency of cache operations.\n", metric)
+	fmt.Fprintf(b, "# TYPE %s histogram\n", metric)
+	for _, name := range names {
+		writeHistogram(b, metric, labels[name]+`,operation="get"`, stats[name].GetLatency)
+		writeHistogram(b, metric, labels[name]+`,operation="set"`, stats[name].SetLatency)
+		writeHistogram(b, metric, labels[name]+`,operation="delete"`, stats[name].DeleteLatency)
+	}
+	return b.Flush()
+}
+
+// PublishExpvar publishes statistics of caches through expvar under name,
+// expvar panics when name is already published
+func (s *Storage) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		result := make(map[string]any)
+		for cacheName, stats := range s.Stats() {
+			cache, _ := s.Get(cacheName)
+			result[cacheName] = map[string]any{
+				"backend":     BackendName(cache),
+				"hits":        stats.Hits,
+				"misses":      stats.Misses,
+				"sets":        stats.Sets,
+				"deletes":     stats.Deletes,
+				"expirations": stats.Expirations,
+				"evictions":   stats.Evictions,
+				"hit_ratio":   stats.HitRatio(),
+			}
+		}
+		return result
+	}))
+}
+
+func writeHistogram(w io.Writer, metric, labels string, h Histogram) {
+	var cumulative uint64
+	for i, bucket := range h.Buckets {
+		if i < len(h.Counts) {
+			cumulative += h.Counts[i]
+		}
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"%s\"} %d\n", metric, labels, formatSeconds(bucket), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", metric, labels, h.Count)
+	fmt.Fprintf(w, "%s_sum{%s} %s\n", metric, labels, formatSeconds(h.Sum))
+	fmt.Fprintf(w, "%s_count{%s} %d\n", metric, labels, h.Count)
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'g', -1, 64)
+}
+
+// escapeLabel escapes label value of Prometheus text exposition format
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"fmt"
+	"github.com/gouef/standards"
+)
+
+// Middleware decorates cache, e.g. by logging, statistics or key prefixing.
+type Middleware func(standards.Cache) standards.Cache
+
+// Unwrapper is cache which decorates another cache.
+type Unwrapper interface {
+	Unwrap() standards.Cache
+}
+
+// Wrap decorates cache by middlewares, first middleware is outermost (called first)
+func Wrap(cache standards.Cache, mws ...Middleware) standards.Cache {
+	for i := len(mws) - 1; i >= 0; i-- {
+		if mws[i] != nil {
+			cache = mws[i](cache)
+		}
+	}
+	return cache
+}
+
+// UnwrapCache returns innermost cache of decorators implementing Unwrapper
+func UnwrapCache(cache standards.Cache) standards.Cache {
+	for {
+		unwrapper, ok := cache.(Unwrapper)
+		if !ok {
+			return cache
+		}
+		cache = unwrapper.Unwrap()
+	}
+}
+
+// StatsMiddleware collects statistics of cache, see StatsCache
+func StatsMiddleware() Middleware {
+	return func(cache standards.Cache) standards.Cache {
+		return NewStatsCache(cache)
+	}
+}
+
+// findCache returns first cache of type T in chain of decorators
+func findCache[T any](cache standards.Cache) (T, bool) {
+	for cache != nil {
+		if found, ok := cache.(T); ok {
+			return found, true
+		}
+		unwrapper, ok := cache.(Unwrapper)
+		if !ok {
+			break
+		}
+		cache = unwrapper.Unwrap()
+	}
+
+	var zero T
+	return zero, false
+}
+
+// newItemOf create empty item of cache, MemoryItem is used when cache doesn't implement ItemFactory
+func newItemOf(cache standards.Cache, key string) standards.CacheItem {
+	if factory, ok := cache.(ItemFactory); ok {
+		return factory.NewItem(key)
+	}
+	return NewMemoryItem(key)
+}
+
+// discardOf drops pending items of cache when it implements Discarder
+func discardOf(cache standards.Cache) error {
+	if discarder, ok := cache.(Discarder); ok {
+		return discarder.Discard()
+	}
+	return nil
+}
+
+// invalidateTagsOf removes items with tags from cache when it implements TagInvalidator
+func invalidateTagsOf(cache standards.Cache, tags ...string) error {
+	if invalidator, ok := cache.(TagInvalidator); ok {
+		return invalidator.InvalidateTags(tags...)
+	}
+	return nil
+}
+
+// purgeOf removes expired items from cache when it implements Purger
+func purgeOf(cache standards.Cache) (int, error) {
+	if purger, ok := cache.(Purger); ok {
+		return purger.Purge()
+	}
+	return 0, nil
+}
+
+// clearPrefixOf removes items with keys starting with prefix from cache when it implements PrefixClearer,
+// otherwise it returns ErrNotSupported, so other prefixes aren't cleared
+func clearPrefixOf(cache standards.Cache, prefix string) error {
+	if clearer, ok := cache.(PrefixClearer); ok {
+		return clearer.ClearPrefix(prefix)
+	}
+	return fmt.Errorf("%w: %T can't clear keys with prefix", ErrNotSupported, cache)
+}
+
+// getItemEOf returns item of cache by CheckedReader, cache without it reports every nil or missed item as ErrMiss
+func getItemEOf(cache standards.Cache, key string) (standards.CacheItem, error) {
+	if reader, ok := cache.(CheckedReader); ok {
+		return reader.GetItemE(key)
+	}
+	return hitOrMiss(cache.GetItem(key))
+}
+
+// hasItemEOf checks item of cache by CheckedReader, cache without it never returns error
+func hasItemEOf(cache standards.Cache, key string) (bool, error) {
+	if reader, ok := cache.(CheckedReader); ok {
+		return reader.HasItemE(key)
+	}
+	return cache.HasItem(key), nil
+}
+
+// saveManyOf saves items at once when cache implements SaveMany, otherwise one by one
+func saveManyOf(cache standards.Cache, items ...standards.CacheItem) error {
+	if saver, ok := cache.(manySaver); ok {
+		return saver.SaveMany(items...)
+	}
+	for _, item := range items {
+		if err := cache.Save(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"github.com/gouef/standards"
+	"time"
+)
+
+// Operation is finished operation of cache reported by ObserveCache.
+type Operation struct {
+	// Name is name of called method, e.g. "GetItem" or "Save", context variants are reported without suffix Context.
+	Name string
+	// Keys are keys of operation, prefix for ClearPrefix, empty for Clear, Commit, Discard, InvalidateTags and Purge.
+	Keys []string
+	// Hits is count of found items of reads, count of purged items of Purge.
+	Hits     int
+	Duration time.Duration
+	Err      error
+}
+
+// ObserveCache is decorator which reports every operation to observe function (logging, tracing, metrics).
+type ObserveCache struct {
+	cache   standards.Cache
+	observe func(op Operation)
+}
+
+// NewObserveCache create ObserveCache decorator of cache
+func NewObserveCache(cache standards.Cache, observe func(op Operation)) *ObserveCache {
+	return &ObserveCache{
+		cache:   cache,
+		observe: observe,
+	}
+}
+
+// ObserveMiddleware reports every operation to observe function, see ObserveCache
+func ObserveMiddleware(observe func(op Operation)) Middleware {
+	return func(cache standards.Cache) standards.Cache {
+		return NewObserveCache(cache, observe)
+	}
+}
+
+// Unwrap returns decorated cache
+func (c *ObserveCache) Unwrap() standards.Cache {
+	return c.cache
+}
+
+// NewItem create empty item of decorated cache
+func (c *ObserveCache) NewItem(key string) standards.CacheItem {
+	return newItemOf(c.cache, key)
+}
+
+func (c *ObserveCache) GetItem(key string) standards.CacheItem {
+	return c.GetItemContext(context.Background(), key)
+}
+
+func (c *ObserveCache) GetItemContext(ctx context.Context, key string) standards.CacheItem {
+	start := time.Now()
+	item := contextOf(c.cache).GetItemContext(ctx, key)
+	hits := 0
+	if item != nil && item.IsHit() {
+		hits = 1
+	}
+	c.report("GetItem", []string{key}, hits, start, nil)
+	return item
+}
+
+// GetItemE returns item of decorated cache, miss is not reported as error, see CheckedReader
+func (c *ObserveCache) GetItemE(key string) (standards.CacheItem, error) {
+	start := time.Now()
+	item, err := getItemEOf(c.cache, key)
+	hits := 0
+	if err == nil {
+		hits = 1
+	}
+	c.report("GetItemE", []string{key}, hits, start, failure(err))
+	return item, err
+}
+
+func (c *ObserveCache) GetItems(keys ...string) []standards.CacheItem {
+	return c.GetItemsContext(context.Background(), keys...)
+}
+
+func (c *ObserveCache) GetItemsContext(ctx context.Context, keys ...string) []standards.CacheItem {
+	start := time.Now()
+	items := contextOf(c.cache).GetItemsContext(ctx, keys...)
+	c.report("GetItems", keys, len(items), start, nil)
+	return items
+}
+
+func (c *ObserveCache) HasItem(key string) bool {
+	return c.HasItemContext(context.Background(), key)
+}
+
+func (c *ObserveCache) HasItemContext(ctx context.Context, key string) bool {
+	start := time.Now()
+	has := contextOf(c.cache).HasItemContext(ctx, key)
+	hits := 0
+	if has {
+		hits = 1
+	}
+	c.report("HasItem", []string{key}, hits, start, nil)
+	return has
+}
+
+// HasItemE checks item of decorated cache, see CheckedReader
+func (c *ObserveCache) HasItemE(key string) (bool, error) {
+	start := time.Now()
+	has, err := hasItemEOf(c.cache, key)
+	hits := 0
+	if has {
+		hits = 1
+	}
+	c.report("HasItemE", []string{key}, hits, start, err)
+	return has, err
+}
+
+func (c *ObserveCache) Clear() error {
+	return c.ClearContext(context.Background())
+}
+
+func (c *ObserveCache) ClearContext(ctx context.Context) error {
+	start := time.Now()
+	err := contextOf(c.cache).ClearContext(ctx)
+	c.report("Clear", nil, 0, start, err)
+	return err
+}
+
+// ClearPrefix removes items with keys starting with prefix when decorated cache supports it
+func (c *ObserveCache) ClearPrefix(prefix string) error {
+	start := time.Now()
+	err := clearPrefixOf(c.cache, prefix)
+	c.report("ClearPrefix", []string{prefix}, 0, start, err)
+	return err
+}
+
+func (c *ObserveCache) DeleteItem(key string) error {
+	return c.DeleteItemContext(context.Background(), key)
+}
+
+func (c *ObserveCache) DeleteItemContext(ctx context.Context, key string) error {
+	start := time.Now()
+	err := contextOf(c.cache).DeleteItemContext(ctx, key)
+	c.report("DeleteItem", []string{key}, 0, start, err)
+	return err
+}
+
+func (c *ObserveCache) DeleteItems(keys ...string) error {
+	return c.DeleteItemsContext(context.Background(), keys...)
+}
+
+func (c *ObserveCache) DeleteItemsContext(ctx context.Context, keys ...string) error {
+	start := time.Now()
+	err := contextOf(c.cache).DeleteItemsContext(ctx, keys...)
+	c.report("DeleteItems", keys, 0, start, err)
+	return err
+}
+
+func (c *ObserveCache) Save(item standards.CacheItem) error {
+	return c.SaveContext(context.Background(), item)
+}
+
+func (c *ObserveCache) SaveContext(ctx context.Context, item standards.CacheItem) error {
+	start := time.Now()
+	err := contextOf(c.cache).SaveContext(ctx, item)
+	c.report("Save", []string{item.GetKey()}, 0, start, err)
+	return err
+}
+
+// SaveMany saves items at once when decorated cache supports it
+func (c *ObserveCache) SaveMany(items ...standards.CacheItem) error {
+	start := time.Now()
+	err := saveManyOf(c.cache, items...)
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		keys = append(keys, item.GetKey())
+	}
+	c.report("SaveMany", keys, 0, start, err)
+	return err
+}
+
+func (c *ObserveCache) SaveDeferred(item standards.CacheItem) error {
+	return c.SaveDeferredContext(context.Background(), item)
+}
+
+func (c *ObserveCache) SaveDeferredContext(ctx context.Context, item standards.CacheItem) error {
+	start := time.Now()
+	err := contextOf(c.cache).SaveDeferredContext(ctx, item)
+	c.report("SaveDeferred", []string{item.GetKey()}, 0, start, err)
+	return err
+}
+
+func (c *ObserveCache) Commit() error {
+	return c.CommitContext(context.Background())
+}
+
+func (c *ObserveCache) CommitContext(ctx context.Context) error {
+	start := time.Now()
+	err := contextOf(c.cache).CommitContext(ctx)
+	c.report("Commit", nil, 0, start, err)
+	return err
+}
+
+// Discard drops pending items when decorated cache supports it
+func (c *ObserveCache) Discard() error {
+	start := time.Now()
+	err := discardOf(c.cache)
+	c.report("Discard", nil, 0, start, err)
+	return err
+}
+
+// InvalidateTags removes items with tags when decorated cache supports tags
+func (c *ObserveCache) InvalidateTags(tags ...string) error {
+	start := time.Now()
+	err := invalidateTagsOf(c.cache, tags...)
+	c.report("InvalidateTags", nil, 0, start, err)
+	return err
+}
+
+// Purge removes expired items when decorated cache supports it
+func (c *ObserveCache) Purge() (int, error) {
+	start := time.Now()
+	purged, err := purgeOf(c.cache)
+	c.report("Purge", nil, purged, start, err)
+	return purged, err
+}
+
+func (c *ObserveCache) report(name string, keys []string, hits int, start time.Time, err error) {
+	c.observe(Operation{
+		Name:     name,
+		Keys:     keys,
+		Hits:     hits,
+		Duration: time.Since(start),
+		Err:      err,
+	})
+}
+
+// failure returns err of read, miss is not failure
+func failure(err error) error {
+	if errors.Is(err, ErrMiss) {
+		return nil
+	}
+	return err
+}
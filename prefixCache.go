@@ -0,0 +1,284 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"github.com/gouef/standards"
+	"io/fs"
+	"strings"
+	"time"
+)
+
+// PrefixCache is decorator which prepends prefix to all keys of decorated cache.
+// Clear removes only prefixed keys, decorated cache must implement PrefixClearer, otherwise Clear returns ErrNotSupported.
+type PrefixCache struct {
+	cache  standards.Cache
+	prefix string
+}
+
+// PrefixClearer is cache which can remove items with keys starting with prefix (Memory, File, Redis, Chain).
+type PrefixClearer interface {
+	ClearPrefix(prefix string) error
+}
+
+// prefixedItem is item of decorated cache which returns key without prefix
+type prefixedItem struct {
+	standards.CacheItem
+	key string
+}
+
+// NewPrefixCache create PrefixCache decorator of cache
+func NewPrefixCache(cache standards.Cache, prefix string) *PrefixCache {
+	return &PrefixCache{
+		cache:  cache,
+		prefix: prefix,
+	}
+}
+
+// PrefixMiddleware prepends prefix to all keys, see PrefixCache
+func PrefixMiddleware(prefix string) Middleware {
+	return func(cache standards.Cache) standards.Cache {
+		return NewPrefixCache(cache, prefix)
+	}
+}
+
+// Unwrap returns decorated cache
+func (c *PrefixCache) Unwrap() standards.Cache {
+	return c.cache
+}
+
+// NewItem create empty item of decorated cache
+func (c *PrefixCache) NewItem(key string) standards.CacheItem {
+	return &prefixedItem{CacheItem: newItemOf(c.cache, c.prefix+key), key: key}
+}
+
+func (c *PrefixCache) GetItem(key string) standards.CacheItem {
+	return c.GetItemContext(context.Background(), key)
+}
+
+func (c *PrefixCache) GetItemContext(ctx context.Context, key string) standards.CacheItem {
+	item := contextOf(c.cache).GetItemContext(ctx, c.prefix+key)
+	if item == nil {
+		return nil
+	}
+	return &prefixedItem{CacheItem: item, key: key}
+}
+
+// GetItemE returns item of decorated cache, see CheckedReader
+func (c *PrefixCache) GetItemE(key string) (standards.CacheItem, error) {
+	item, err := getItemEOf(c.cache, c.prefix+key)
+	if err != nil {
+		return nil, err
+	}
+	return &prefixedItem{CacheItem: item, key: key}, nil
+}
+
+func (c *PrefixCache) GetItems(keys ...string) []standards.CacheItem {
+	return c.GetItemsContext(context.Background(), keys...)
+}
+
+func (c *PrefixCache) GetItemsContext(ctx context.Context, keys ...string) []standards.CacheItem {
+	var items []standards.CacheItem
+	for _, item := range contextOf(c.cache).GetItemsContext(ctx, c.keys(keys)...) {
+		if item == nil {
+			continue
+		}
+		key := strings.TrimPrefix(item.GetKey(), c.prefix)
+		items = append(items, &prefixedItem{CacheItem: item, key: key})
+	}
+	return items
+}
+
+func (c *PrefixCache) HasItem(key string) bool {
+	return c.HasItemContext(context.Background(), key)
+}
+
+func (c *PrefixCache) HasItemContext(ctx context.Context, key string) bool {
+	return contextOf(c.cache).HasItemContext(ctx, c.prefix+key)
+}
+
+// HasItemE checks item of decorated cache, see CheckedReader
+func (c *PrefixCache) HasItemE(key string) (bool, error) {
+	return hasItemEOf(c.cache, c.prefix+key)
+}
+
+func (c *PrefixCache) Clear() error {
+	return c.ClearContext(context.Background())
+}
+
+// ClearContext removes items with prefix, other keys of decorated cache are kept
+func (c *PrefixCache) ClearContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return clearPrefixOf(c.cache, c.prefix)
+}
+
+// ClearPrefix removes items with keys starting with prefix (inside prefix of PrefixCache)
+func (c *PrefixCache) ClearPrefix(prefix string) error {
+	return clearPrefixOf(c.cache, c.prefix+prefix)
+}
+
+func (c *PrefixCache) DeleteItem(key string) error {
+	return c.DeleteItemContext(context.Background(), key)
+}
+
+func (c *PrefixCache) DeleteItemContext(ctx context.Context, key string) error {
+	return contextOf(c.cache).DeleteItemContext(ctx, c.prefix+key)
+}
+
+func (c *PrefixCache) DeleteItems(keys ...string) error {
+	return c.DeleteItemsContext(context.Background(), keys...)
+}
+
+func (c *PrefixCache) DeleteItemsContext(ctx context.Context, keys ...string) error {
+	return contextOf(c.cache).DeleteItemsContext(ctx, c.keys(keys)...)
+}
+
+func (c *PrefixCache) Save(item standards.CacheItem) error {
+	return c.SaveContext(context.Background(), item)
+}
+
+func (c *PrefixCache) SaveContext(ctx context.Context, item standards.CacheItem) error {
+	inner, err := c.item(item)
+	if inner == nil || err != nil {
+		return err
+	}
+	return contextOf(c.cache).SaveContext(ctx, inner)
+}
+
+// SaveMany saves items at once when decorated cache supports it
+func (c *PrefixCache) SaveMany(items ...standards.CacheItem) error {
+	inners := make([]standards.CacheItem, 0, len(items))
+	for _, item := range items {
+		inner, err := c.item(item)
+		if err != nil {
+			return err
+		}
+		if inner != nil {
+			inners = append(inners, inner)
+		}
+	}
+	return saveManyOf(c.cache, inners...)
+}
+
+func (c *PrefixCache) SaveDeferred(item standards.CacheItem) error {
+	return c.SaveDeferredContext(context.Background(), item)
+}
+
+func (c *PrefixCache) SaveDeferredContext(ctx context.Context, item standards.CacheItem) error {
+	inner, err := c.item(item)
+	if inner == nil || err != nil {
+		return err
+	}
+	return contextOf(c.cache).SaveDeferredContext(ctx, inner)
+}
+
+func (c *PrefixCache) Commit() error {
+	return c.CommitContext(context.Background())
+}
+
+func (c *PrefixCache) CommitContext(ctx context.Context) error {
+	return contextOf(c.cache).CommitContext(ctx)
+}
+
+// Discard drops pending items when decorated cache supports it
+func (c *PrefixCache) Discard() error {
+	return discardOf(c.cache)
+}
+
+// InvalidateTags removes items with tags when decorated cache supports tags, tags are not prefixed
+func (c *PrefixCache) InvalidateTags(tags ...string) error {
+	return invalidateTagsOf(c.cache, tags...)
+}
+
+// Purge removes expired items when decorated cache supports it
+func (c *PrefixCache) Purge() (int, error) {
+	return purgeOf(c.cache)
+}
+
+// item returns item of decorated cache with prefixed key, nil item means expired item was deleted
+func (c *PrefixCache) item(item standards.CacheItem) (standards.CacheItem, error) {
+	if prefixed, ok := item.(*prefixedItem); ok {
+		return prefixed.CacheItem, nil
+	}
+
+	key := c.prefix + item.GetKey()
+	ttl, expired := remainingTTL(item)
+	if expired {
+		if err := c.cache.DeleteItem(key); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		return nil, nil
+	}
+	return copyItem(c.cache, key, item, ttl)
+}
+
+func (c *PrefixCache) keys(keys []string) []string {
+	prefixed := make([]string, 0, len(keys))
+	for _, key := range keys {
+		prefixed = append(prefixed, c.prefix+key)
+	}
+	return prefixed
+}
+
+func (i *prefixedItem) GetKey() string {
+	return i.key
+}
+
+func (i *prefixedItem) Set(value any, ttl time.Duration) (standards.CacheItem, error) {
+	_, err := i.CacheItem.Set(value, ttl)
+	return i, err
+}
+
+func (i *prefixedItem) ExpiresAt(expiration time.Time) (standards.CacheItem, error) {
+	_, err := i.CacheItem.ExpiresAt(expiration)
+	return i, err
+}
+
+// SetComputeTime sets time which computation of value took
+func (i *prefixedItem) SetComputeTime(d time.Duration) {
+	setComputeTime(i.CacheItem, d)
+}
+
+// GetComputeTime returns time which computation of value took
+func (i *prefixedItem) GetComputeTime() time.Duration {
+	return computeTimeOf(i.CacheItem)
+}
+
+// Tag adds tags to item
+func (i *prefixedItem) Tag(tags ...string) standards.CacheItem {
+	if tagged, ok := i.CacheItem.(TaggedItem); ok {
+		tagged.Tag(tags...)
+	}
+	return i
+}
+
+// GetTags returns tags of item
+func (i *prefixedItem) GetTags() []string {
+	if tagged, ok := i.CacheItem.(TaggedItem); ok {
+		return tagged.GetTags()
+	}
+	return nil
+}
+
+// GetExpiration returns expiration of item, ok is false when item never expires
+func (i *prefixedItem) GetExpiration() (time.Time, bool) {
+	if expiring, ok := i.CacheItem.(ExpiringItem); ok {
+		return expiring.GetExpiration()
+	}
+	return time.Time{}, false
+}
+
+// ExpiresAfterIdle sets sliding expiration of item when item of decorated cache supports it
+func (i *prefixedItem) ExpiresAfterIdle(idle, maxLifetime time.Duration) standards.CacheItem {
+	if sliding, ok := i.CacheItem.(SlidingItem); ok {
+		sliding.ExpiresAfterIdle(idle, maxLifetime)
+	}
+	return i
+}
+
+// GetIdleTimeout returns idle timeout and max expiration of item, idle is 0 when item doesn't slide
+func (i *prefixedItem) GetIdleTimeout() (time.Duration, time.Time) {
+	return idleTimeoutOf(i.CacheItem)
+}
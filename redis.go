@@ -3,71 +3,785 @@ package cache
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/gouef/standards"
 	redisLib "github.com/redis/go-redis/v9"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
+// REDIS_TAG_PREFIX is prefix of sets with keys of tag
+const REDIS_TAG_PREFIX = "__tag:"
+
+// REDIS_SCAN_COUNT is count of keys scanned and removed at once by Clear
+const REDIS_SCAN_COUNT = 1000
+
+// REDIS_TAG_SCRIPT adds key of item (ARGV[1]) to tag sets (KEYS) and keeps TTL of every set at least as long
+// as TTL of item in milliseconds (ARGV[2], 0 means item doesn't expire). Set without TTL gets one only
+// when item is its only member, otherwise it can contain items which don't expire.
+const REDIS_TAG_SCRIPT = `
+local ttl = tonumber(ARGV[2])
+for _, tag in ipairs(KEYS) do
+	redis.call('SADD', tag, ARGV[1])
+	if ttl <= 0 then
+		redis.call('PERSIST', tag)
+	else
+		local current = redis.call('PTTL', tag)
+		if (current == -1 and redis.call('SCARD', tag) == 1) or (current >= 0 and current < ttl) then
+			redis.call('PEXPIRE', tag, ttl)
+		end
+	end
+end
+return 0`
+
+// REDIS_COMPUTE_PREFIX is prefix of keys with compute time of items, they are stored with XFetchBeta
+const REDIS_COMPUTE_PREFIX = "__compute:"
+
+// REDIS_IDLE_PREFIX is prefix of keys with idle timeout of items, they are stored with SlidingExpiration
+const REDIS_IDLE_PREFIX = "__idle:"
+
 type Redis struct {
 	client *redisLib.Client
 	ctx    context.Context
+	codec  Codec
+	prefix string
+	// deferred items waiting for Commit
+	deferred map[string]*RedisItem
+	mu       sync.Mutex
+	log      cacheLog
+	// ownsClient is set when client was created by cache (e.g. from DSN), so Close closes it
+	ownsClient     bool
+	disableFlushDB bool
+	xfetchBeta     float64
+	sliding        bool
+}
+
+// RedisOptions configure Redis cache.
+type RedisOptions struct {
+	// Codec serializes values, nil means values are passed to go-redis as they are (stored as strings).
+	Codec Codec
+	// Prefix is prepended to all keys, Clear removes only keys with Prefix.
+	// Without Prefix Clear flushes current database (FLUSHDB), unless DisableFlushDB is set.
+	Prefix string
+	// Log configures logging of errors, corrupt values and slow operations.
+	Log LogOptions
+	// XFetchBeta enables probabilistic early expiration (XFetch) of items with compute time, 0 means disabled.
+	// Compute time is stored in extra key and reads fetch TTL of key too.
+	XFetchBeta float64
+	// SlidingExpiration enables sliding expiration of items with idle timeout (see SlidingItem).
+	// Idle timeout is stored in extra key, reads fetch it too and hits push TTL of keys forward by PEXPIRE.
+	SlidingExpiration bool
+	// DisableFlushDB makes Clear without Prefix return ErrFlushNotAllowed instead of flushing current database.
+	DisableFlushDB bool
 }
 
 func NewRedis(client *redisLib.Client) standards.Cache {
-	return &Redis{
-		client: client,
-		ctx:    context.Background(),
+	return NewRedisWithOptions(client, RedisOptions{})
+}
+
+// NewRedisWithOptions create new instance of Redis with options
+func NewRedisWithOptions(client *redisLib.Client, options RedisOptions) *Redis {
+	c := &Redis{
+		client:         client,
+		ctx:            context.Background(),
+		codec:          options.Codec,
+		prefix:         options.Prefix,
+		xfetchBeta:     options.XFetchBeta,
+		sliding:        options.SlidingExpiration,
+		disableFlushDB: options.DisableFlushDB,
 	}
+	c.log.set(options.Log)
+	return c
+}
+
+// SetLogOptions configure logging of errors, corrupt values and slow operations
+func (c *Redis) SetLogOptions(options LogOptions) {
+	c.log.set(options)
+}
+
+// NewItem create empty item for Redis cache
+func (c *Redis) NewItem(key string) standards.CacheItem {
+	return NewRedisItem(key)
 }
 
 func (c *Redis) GetItem(key string) standards.CacheItem {
-	value, err := c.client.Get(c.ctx, key).Result()
-	if err == redisLib.Nil {
-		return nil
-	}
-	return &RedisItem{key: key, value: value, hit: true}
+	return c.GetItemContext(c.ctx, key)
 }
 
 func (c *Redis) GetItems(keys ...string) []standards.CacheItem {
-	var items []standards.CacheItem
-	for _, key := range keys {
-		item := c.GetItem(key)
-		if item != nil && item.Get() != "" {
-			items = append(items, c.GetItem(key))
-		}
-	}
-	return items
+	return c.GetItemsContext(c.ctx, keys...)
 }
 
 func (c *Redis) HasItem(key string) bool {
-	item, err := c.client.Get(c.ctx, key).Result()
-	return err != redisLib.Nil && item != ""
+	return c.HasItemContext(c.ctx, key)
 }
 
+// Clear removes all keys with prefix, without prefix it flushes current database unless DisableFlushDB is set
 func (c *Redis) Clear() error {
-	return c.client.FlushAll(c.ctx).Err()
+	return c.ClearContext(c.ctx)
 }
 
 func (c *Redis) DeleteItem(key string) error {
-	return c.client.Del(c.ctx, key).Err()
+	return c.DeleteItemContext(c.ctx, key)
 }
 
 func (c *Redis) DeleteItems(keys ...string) error {
-	return c.client.Del(c.ctx, keys...).Err()
+	return c.DeleteItemsContext(c.ctx, keys...)
 }
 
 func (c *Redis) Save(item standards.CacheItem) error {
+	return c.SaveContext(c.ctx, item)
+}
+
+func (c *Redis) SaveDeferred(item standards.CacheItem) error {
+	return c.SaveDeferredContext(c.ctx, item)
+}
+
+func (c *Redis) Commit() error {
+	return c.CommitContext(c.ctx)
+}
+
+// Discard drops all pending items
+func (c *Redis) Discard() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.deferred = nil
+	return nil
+}
+
+// PingContext checks connection to Redis server
+func (c *Redis) PingContext(ctx context.Context) error {
+	if err := c.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("%w: %w", ErrBackendUnavailable, err)
+	}
+	return nil
+}
+
+// Close closes Redis client created by cache (e.g. from DSN), client passed to constructor is left open
+func (c *Redis) Close() error {
+	if !c.ownsClient {
+		return nil
+	}
+	return c.client.Close()
+}
+
+// InvalidateTags removes all items with at least one of tags
+func (c *Redis) InvalidateTags(tags ...string) error {
+	return c.InvalidateTagsContext(c.ctx, tags...)
+}
+
+func (c *Redis) GetItemContext(ctx context.Context, key string) standards.CacheItem {
+	item, _ := c.GetItemEContext(ctx, key)
+	return item
+}
+
+// GetItemE returns item of key, error is ErrMiss for missing key, wraps ErrBackendUnavailable
+// for Redis errors and ErrCorruptEntry when value can't be decoded
+func (c *Redis) GetItemE(key string) (standards.CacheItem, error) {
+	return c.GetItemEContext(c.ctx, key)
+}
+
+// GetItemEContext returns item of key, see GetItemE
+func (c *Redis) GetItemEContext(ctx context.Context, key string) (standards.CacheItem, error) {
+	if item := c.getDeferred(key); item != nil {
+		return hitOrMiss(item)
+	}
+
+	defer c.log.slow("GetItem", key, time.Now())
+
+	item := &RedisItem{key: key, hit: true}
+	value, err := c.get(ctx, item)
+	if err == redisLib.Nil || (err == nil && value == "") {
+		// empty value is missing like in GetItems
+		return nil, ErrMiss
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			_ = c.log.failed("GetItem", key, err)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrBackendUnavailable, err)
+	}
+
+	item.value, err = c.decode(value)
+	if err != nil {
+		c.log.corrupt("GetItem", key, err)
+		return nil, fmt.Errorf("%w: %w", ErrCorruptEntry, err)
+	}
+	if !c.hit(ctx, item) {
+		return nil, ErrMiss
+	}
+	return item, nil
+}
+
+// hit applies XFetch and sliding expiration to found item, it reports false when item expires early
+func (c *Redis) hit(ctx context.Context, item *RedisItem) bool {
+	now := time.Now()
+	if expiresEarly(item, c.xfetchBeta, now) {
+		return false
+	}
+	if item.idle > 0 {
+		c.slide(ctx, item, now)
+	}
+	return true
+}
+
+// slide pushes TTL of item with idle timeout and of its extra keys forward, failure is only logged
+func (c *Redis) slide(ctx context.Context, item *RedisItem, now time.Time) {
+	expiration := idleExpiration(item.idle, item.maxExpiration, now)
+	ttl := expiration.Sub(now)
+	if ttl <= 0 {
+		return
+	}
+
+	_, err := c.client.Pipelined(ctx, func(pipe redisLib.Pipeliner) error {
+		pipe.PExpire(ctx, c.key(item.GetKey()), ttl)
+		pipe.PExpire(ctx, c.idleKey(item.GetKey()), ttl)
+		if c.xfetchBeta > 0 {
+			pipe.PExpire(ctx, c.computeKey(item.GetKey()), ttl)
+		}
+		return nil
+	})
+	if err != nil {
+		_ = c.log.failed("GetItem", item.GetKey(), err)
+		return
+	}
+	item.expiration = expiration
+}
+
+// redisRead is queued read of value of item and of its extra keys
+type redisRead struct {
+	get, compute, idle *redisLib.StringCmd
+	pttl               *redisLib.DurationCmd
+}
+
+// get reads value of item, with XFetchBeta it reads expiration and compute time of item too
+// and with SlidingExpiration its idle timeout
+func (c *Redis) get(ctx context.Context, item *RedisItem) (string, error) {
+	if c.xfetchBeta <= 0 && !c.sliding {
+		return c.client.Get(ctx, c.key(item.GetKey())).Result()
+	}
+
+	var read redisRead
+	_, _ = c.client.Pipelined(ctx, func(pipe redisLib.Pipeliner) error {
+		read = c.queueGet(ctx, pipe, item.GetKey())
+		return nil
+	})
+	return read.result(item)
+}
+
+// queueGet queues read of value of key and of extra keys of XFetch and sliding expiration to pipe
+func (c *Redis) queueGet(ctx context.Context, pipe redisLib.Pipeliner, key string) redisRead {
+	read := redisRead{get: pipe.Get(ctx, c.key(key))}
+	if c.xfetchBeta > 0 {
+		read.pttl = pipe.PTTL(ctx, c.key(key))
+		read.compute = pipe.Get(ctx, c.computeKey(key))
+	}
+	if c.sliding {
+		read.idle = pipe.Get(ctx, c.idleKey(key))
+	}
+	return read
+}
+
+// result sets expiration, compute time and idle timeout of item and returns its value
+func (r redisRead) result(item *RedisItem) (string, error) {
+	// expiration, compute time and idle timeout are optional
+	if r.pttl != nil {
+		if ttl, err := r.pttl.Result(); err == nil && ttl > 0 {
+			item.expiration = time.Now().Add(ttl)
+		}
+	}
+	if r.compute != nil {
+		if computeTime, err := r.compute.Int64(); err == nil {
+			item.computeTime = time.Duration(computeTime)
+		}
+	}
+	if r.idle != nil {
+		if value, err := r.idle.Result(); err == nil {
+			item.idle, item.maxExpiration = parseIdleTimeout(value)
+		}
+	}
+	return r.get.Result()
+}
+
+// GetItemsContext reads all keys with one MGET, missing keys are skipped.
+// With XFetchBeta or SlidingExpiration keys are read with their extra keys in one pipeline.
+func (c *Redis) GetItemsContext(ctx context.Context, keys ...string) []standards.CacheItem {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	defer c.log.slow("GetItems", "", time.Now())
+	if c.xfetchBeta > 0 || c.sliding {
+		return c.getItemsPipelined(ctx, keys)
+	}
+
+	values, err := c.client.MGet(ctx, c.keys(keys)...).Result()
+	if err != nil {
+		_ = c.log.failed("GetItems", "", err)
+		// pending items are still visible
+		values = make([]any, len(keys))
+	}
+
+	var items []standards.CacheItem
+	for i, key := range keys {
+		if item := c.getDeferred(key); item != nil {
+			items = append(items, item)
+			continue
+		}
+
+		value, ok := values[i].(string)
+		if !ok || value == "" {
+			continue
+		}
+		decoded, err := c.decode(value)
+		if err != nil {
+			c.log.corrupt("GetItems", key, err)
+			continue
+		}
+		items = append(items, &RedisItem{key: key, value: decoded, hit: true})
+	}
+	return items
+}
+
+// getItemsPipelined reads keys with extra keys of XFetch and sliding expiration in one pipeline, missing keys are skipped
+func (c *Redis) getItemsPipelined(ctx context.Context, keys []string) []standards.CacheItem {
+	pending := make([]*RedisItem, len(keys))
+	reads := make([]redisRead, len(keys))
+	_, err := c.client.Pipelined(ctx, func(pipe redisLib.Pipeliner) error {
+		for i, key := range keys {
+			if pending[i] = c.getDeferred(key); pending[i] == nil {
+				reads[i] = c.queueGet(ctx, pipe, key)
+			}
+		}
+		return nil
+	})
+	if err != nil && err != redisLib.Nil {
+		_ = c.log.failed("GetItems", "", err)
+	}
+
+	var items []standards.CacheItem
+	for i, key := range keys {
+		if pending[i] != nil {
+			items = append(items, pending[i])
+			continue
+		}
+
+		item := &RedisItem{key: key, hit: true}
+		value, err := reads[i].result(item)
+		if err != nil || value == "" {
+			continue
+		}
+		if item.value, err = c.decode(value); err != nil {
+			c.log.corrupt("GetItems", key, err)
+			continue
+		}
+		if c.hit(ctx, item) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (c *Redis) HasItemContext(ctx context.Context, key string) bool {
+	has, _ := c.HasItemEContext(ctx, key)
+	return has
+}
+
+// HasItemE reports if key is in cache, misses are not errors, Redis errors wrap ErrBackendUnavailable
+func (c *Redis) HasItemE(key string) (bool, error) {
+	return c.HasItemEContext(c.ctx, key)
+}
+
+// HasItemEContext reports if key is in cache, see HasItemE. Found item expires early by XFetch and slides like by GetItem.
+func (c *Redis) HasItemEContext(ctx context.Context, key string) (bool, error) {
+	return hasItem(c.GetItemEContext(ctx, key))
+}
+
+// ClearContext removes all keys with prefix, without prefix it flushes current database unless DisableFlushDB is set
+func (c *Redis) ClearContext(ctx context.Context) error {
+	return c.log.failed("Clear", "", c.clear(ctx))
+}
+
+func (c *Redis) clear(ctx context.Context) error {
+	if c.prefix == "" && c.disableFlushDB {
+		return ErrFlushNotAllowed
+	}
+	_ = c.Discard()
+	if c.prefix == "" {
+		return c.client.FlushDB(ctx).Err()
+	}
+
+	return c.unlinkMatching(ctx, escapePattern(c.prefix)+"*")
+}
+
+// ClearPrefix removes items with keys starting with prefix (inside Prefix) and their extra keys by SCAN
+func (c *Redis) ClearPrefix(prefix string) error {
+	return c.ClearPrefixContext(c.ctx, prefix)
+}
+
+// ClearPrefixContext removes items with keys starting with prefix, see ClearPrefix
+func (c *Redis) ClearPrefixContext(ctx context.Context, prefix string) error {
+	c.mu.Lock()
+	for key := range c.deferred {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.deferred, key)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, match := range []string{c.key(prefix), c.computeKey(prefix), c.idleKey(prefix)} {
+		if err := c.unlinkMatching(ctx, escapePattern(match)+"*"); err != nil {
+			return c.log.failed("ClearPrefix", prefix, err)
+		}
+	}
+	return nil
+}
+
+// unlinkMatching removes keys matching pattern by SCAN and UNLINK
+func (c *Redis) unlinkMatching(ctx context.Context, match string) error {
+	var cursor uint64
+	for {
+		keys, next, err := c.client.Scan(ctx, cursor, match, REDIS_SCAN_COUNT).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := c.client.Unlink(ctx, keys...).Err(); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+func (c *Redis) DeleteItemContext(ctx context.Context, key string) error {
+	c.forgetDeferred(key)
+	return c.log.failed("DeleteItem", key, c.client.Del(ctx, c.deletedKeys([]string{key})...).Err())
+}
+
+func (c *Redis) DeleteItemsContext(ctx context.Context, keys ...string) error {
+	c.forgetDeferred(keys...)
+	return c.log.failed("DeleteItems", "", c.client.Del(ctx, c.deletedKeys(keys)...).Err())
+}
+
+func (c *Redis) SaveContext(ctx context.Context, item standards.CacheItem) error {
 	rItem, ok := item.(*RedisItem)
 	if !ok {
 		return errors.New("invalid cache item type")
 	}
-	return c.client.Set(c.ctx, rItem.GetKey(), rItem.Get(), rItem.expiration.Sub(time.Now())).Err()
+	c.forgetDeferred(rItem.GetKey())
+	defer c.log.slow("Save", rItem.GetKey(), time.Now())
+
+	value, err := c.encode(rItem.Get())
+	if err != nil {
+		return err
+	}
+	key := c.key(rItem.GetKey())
+	if len(rItem.tags) == 0 && c.xfetchBeta <= 0 && !c.sliding {
+		return c.log.failed("Save", rItem.GetKey(), c.client.Set(ctx, key, value, rItem.expiration.Sub(time.Now())).Err())
+	}
+
+	_, err = c.client.Pipelined(ctx, func(pipe redisLib.Pipeliner) error {
+		c.pipeSet(ctx, pipe, rItem, value)
+		return nil
+	})
+	return c.log.failed("Save", rItem.GetKey(), err)
 }
 
-func (c *Redis) SaveDeferred(item standards.CacheItem) error {
-	return c.Save(item)
+// SaveMany saves items with one pipeline, every item keeps its own TTL
+func (c *Redis) SaveMany(items ...standards.CacheItem) error {
+	return c.SaveManyContext(c.ctx, items...)
 }
 
-func (c *Redis) Commit() error {
+// SaveManyContext saves items with one pipeline, every item keeps its own TTL
+func (c *Redis) SaveManyContext(ctx context.Context, items ...standards.CacheItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	rItems := make([]*RedisItem, 0, len(items))
+	values := make([]any, 0, len(items))
+	for _, item := range items {
+		rItem, ok := item.(*RedisItem)
+		if !ok {
+			return errors.New("invalid cache item type")
+		}
+		value, err := c.encode(rItem.Get())
+		if err != nil {
+			return err
+		}
+		rItems = append(rItems, rItem)
+		values = append(values, value)
+		c.forgetDeferred(rItem.GetKey())
+	}
+
+	defer c.log.slow("SaveMany", "", time.Now())
+	_, err := c.client.Pipelined(ctx, func(pipe redisLib.Pipeliner) error {
+		for i, rItem := range rItems {
+			c.pipeSet(ctx, pipe, rItem, values[i])
+		}
+		return nil
+	})
+	return c.log.failed("SaveMany", "", err)
+}
+
+// SaveDeferredContext keeps item pending until Commit, pending item is returned by GetItem
+func (c *Redis) SaveDeferredContext(ctx context.Context, item standards.CacheItem) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	rItem, ok := item.(*RedisItem)
+	if !ok {
+		return errors.New("invalid cache item type")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.deferred == nil {
+		c.deferred = make(map[string]*RedisItem)
+	}
+	c.deferred[rItem.GetKey()] = rItem
 	return nil
 }
+
+// CommitContext saves all pending items in one MULTI/EXEC transaction,
+// items stay pending when transaction fails
+func (c *Redis) CommitContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// pending items are copied, so reads and writes don't wait for transaction
+	c.mu.Lock()
+	pending := make(map[string]*RedisItem, len(c.deferred))
+	for key, item := range c.deferred {
+		pending[key] = item
+	}
+	c.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	values := make(map[string]any, len(pending))
+	for key, item := range pending {
+		value, err := c.encode(item.Get())
+		if err != nil {
+			return err
+		}
+		values[key] = value
+	}
+
+	defer c.log.slow("Commit", "", time.Now())
+	_, err := c.client.TxPipelined(ctx, func(pipe redisLib.Pipeliner) error {
+		for key, item := range pending {
+			c.pipeSet(ctx, pipe, item, values[key])
+		}
+		return nil
+	})
+	if err != nil {
+		return c.log.failed("Commit", "", err)
+	}
+
+	// items deferred again during transaction stay pending
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, item := range pending {
+		if c.deferred[key] == item {
+			delete(c.deferred, key)
+		}
+	}
+	return nil
+}
+
+// InvalidateTagsContext removes all items with at least one of tags
+func (c *Redis) InvalidateTagsContext(ctx context.Context, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	tagKeys := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tagKeys = append(tagKeys, c.tagKey(tag))
+	}
+
+	c.mu.Lock()
+	for key, item := range c.deferred {
+		if hasAnyTag(item.tags, tags) {
+			delete(c.deferred, key)
+		}
+	}
+	c.mu.Unlock()
+
+	keys, err := c.client.SUnion(ctx, tagKeys...).Result()
+	if err != nil {
+		return c.log.failed("InvalidateTags", "", err)
+	}
+	// members are keys with prefix, extra keys of items (compute time, idle timeout) are removed too
+	for i, key := range keys {
+		keys[i] = strings.TrimPrefix(key, c.prefix)
+	}
+	return c.log.failed("InvalidateTags", "", c.client.Del(ctx, append(c.deletedKeys(keys), tagKeys...)...).Err())
+}
+
+// pipeSet queues SET of item and adds it to its tag sets
+func (c *Redis) pipeSet(ctx context.Context, pipe redisLib.Pipeliner, item *RedisItem, value any) {
+	key := c.key(item.GetKey())
+	ttl := item.expiration.Sub(time.Now())
+	pipe.Set(ctx, key, value, ttl)
+	if len(item.tags) > 0 {
+		tagKeys := make([]string, 0, len(item.tags))
+		for _, tag := range item.tags {
+			tagKeys = append(tagKeys, c.tagKey(tag))
+		}
+		var tagTTL int64
+		if !item.expiration.IsZero() && !item.KeepTTL {
+			tagTTL = max(ttl.Milliseconds(), 1)
+		}
+		pipe.Eval(ctx, REDIS_TAG_SCRIPT, tagKeys, key, tagTTL)
+	}
+	if c.xfetchBeta > 0 {
+		if item.computeTime > 0 {
+			pipe.Set(ctx, c.computeKey(item.GetKey()), int64(item.computeTime), ttl)
+		} else {
+			pipe.Del(ctx, c.computeKey(item.GetKey()))
+		}
+	}
+	if c.sliding {
+		if item.idle > 0 {
+			pipe.Set(ctx, c.idleKey(item.GetKey()), formatIdleTimeout(item.idle, item.maxExpiration), ttl)
+		} else {
+			pipe.Del(ctx, c.idleKey(item.GetKey()))
+		}
+	}
+}
+
+// formatIdleTimeout returns stored form of idle timeout and max expiration, "<idle ns>:<max expiration unix ns>"
+func formatIdleTimeout(idle time.Duration, maxExpiration time.Time) string {
+	var maxNano int64
+	if !maxExpiration.IsZero() {
+		maxNano = maxExpiration.UnixNano()
+	}
+	return strconv.FormatInt(int64(idle), 10) + ":" + strconv.FormatInt(maxNano, 10)
+}
+
+// parseIdleTimeout returns idle timeout and max expiration stored by formatIdleTimeout, idle is 0 for invalid value
+func parseIdleTimeout(value string) (time.Duration, time.Time) {
+	idleValue, maxValue, _ := strings.Cut(value, ":")
+	idle, err := strconv.ParseInt(idleValue, 10, 64)
+	if err != nil || idle <= 0 {
+		return 0, time.Time{}
+	}
+	maxNano, err := strconv.ParseInt(maxValue, 10, 64)
+	if err != nil || maxNano <= 0 {
+		return time.Duration(idle), time.Time{}
+	}
+	return time.Duration(idle), time.Unix(0, maxNano)
+}
+
+// getDeferred returns pending item of key
+func (c *Redis) getDeferred(key string) *RedisItem {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.deferred[key]
+}
+
+// forgetDeferred drops pending items of keys
+func (c *Redis) forgetDeferred(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		delete(c.deferred, key)
+	}
+}
+
+// computeKey returns key of compute time of item
+func (c *Redis) computeKey(key string) string {
+	return c.prefix + REDIS_COMPUTE_PREFIX + key
+}
+
+// deletedKeys returns keys with prefix, with XFetchBeta also keys of their compute time
+// and with SlidingExpiration keys of their idle timeout, keys of caller are not modified
+func (c *Redis) deletedKeys(keys []string) []string {
+	deleted := make([]string, 0, len(keys)*3)
+	for _, key := range keys {
+		deleted = append(deleted, c.key(key))
+	}
+	for _, key := range keys {
+		if c.xfetchBeta > 0 {
+			deleted = append(deleted, c.computeKey(key))
+		}
+		if c.sliding {
+			deleted = append(deleted, c.idleKey(key))
+		}
+	}
+	return deleted
+}
+
+// idleKey returns key of idle timeout of item
+func (c *Redis) idleKey(key string) string {
+	return c.prefix + REDIS_IDLE_PREFIX + key
+}
+
+// tagKey returns key of set with keys of tag
+func (c *Redis) tagKey(tag string) string {
+	return c.prefix + REDIS_TAG_PREFIX + tag
+}
+
+// key returns key with prefix
+func (c *Redis) key(key string) string {
+	return c.prefix + key
+}
+
+func (c *Redis) keys(keys []string) []string {
+	if c.prefix == "" {
+		return keys
+	}
+
+	prefixed := make([]string, 0, len(keys))
+	for _, key := range keys {
+		prefixed = append(prefixed, c.key(key))
+	}
+	return prefixed
+}
+
+func (c *Redis) encode(value any) (any, error) {
+	if c.codec == nil {
+		return value, nil
+	}
+	return c.codec.Marshal(value)
+}
+
+func (c *Redis) decode(value string) (any, error) {
+	if c.codec == nil {
+		return value, nil
+	}
+
+	var decoded any
+	if err := c.codec.Unmarshal([]byte(value), &decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+// escapePattern escapes special characters of Redis glob pattern
+func escapePattern(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\', '^', '-':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"github.com/gouef/standards"
+	"slices"
 	"time"
 )
 
@@ -11,6 +12,12 @@ type RedisItem struct {
 	hit        bool
 	expiration time.Time
 	KeepTTL    bool
+	tags       []string
+	// computeTime is time which computation of value took
+	computeTime time.Duration
+	// idle is idle timeout of sliding expiration, maxExpiration caps sliding
+	idle          time.Duration
+	maxExpiration time.Time
 }
 
 func NewRedisItem(key string) *RedisItem {
@@ -58,3 +65,49 @@ func (r *RedisItem) ExpiresAfter(t time.Duration) {
 		r.ExpiresAt(time.Now().Add(t))
 	}
 }
+
+// ExpiresAfterIdle sets idle timeout of item, every hit pushes expiration forward by idle until maxLifetime.
+// Redis slides expiration only with SlidingExpiration option.
+func (r *RedisItem) ExpiresAfterIdle(idle, maxLifetime time.Duration) standards.CacheItem {
+	now := time.Now()
+	r.idle = max(idle, 0)
+	r.maxExpiration = maxExpirationAfter(maxLifetime, now)
+	if hasIdleExpiration(r.idle, r.maxExpiration) {
+		r.ExpiresAt(idleExpiration(r.idle, r.maxExpiration, now))
+	}
+	return r
+}
+
+// GetIdleTimeout returns idle timeout and max expiration of item
+func (r *RedisItem) GetIdleTimeout() (time.Duration, time.Time) {
+	return r.idle, r.maxExpiration
+}
+
+// SetComputeTime sets time which computation of value took, it is used by XFetch
+func (r *RedisItem) SetComputeTime(d time.Duration) {
+	r.computeTime = d
+}
+
+// GetComputeTime returns time which computation of value took
+func (r *RedisItem) GetComputeTime() time.Duration {
+	return r.computeTime
+}
+
+// GetExpiration returns expiration of item, ok is false when item never expires
+func (r *RedisItem) GetExpiration() (time.Time, bool) {
+	if r.KeepTTL || r.expiration.IsZero() {
+		return time.Time{}, false
+	}
+	return r.expiration, true
+}
+
+// Tag adds tags to item
+func (r *RedisItem) Tag(tags ...string) standards.CacheItem {
+	r.tags = appendTags(r.tags, tags...)
+	return r
+}
+
+// GetTags returns tags of item
+func (r *RedisItem) GetTags() []string {
+	return slices.Clone(r.tags)
+}
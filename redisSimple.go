@@ -0,0 +1,38 @@
+package cache
+
+import (
+	redisLib "github.com/redis/go-redis/v9"
+)
+
+// RedisSimple is Redis cache with simple key/value API of FileSimple.
+// GetMultiply uses one MGET and SetMultiply one pipeline.
+type RedisSimple struct {
+	*Simple
+	redis *Redis
+}
+
+// NewRedisSimple create RedisSimple instance with not allowed default value nil
+func NewRedisSimple(client *redisLib.Client) *RedisSimple {
+	return NewRedisSimpleWithOptions(client, RedisOptions{})
+}
+
+// NewRedisSimpleWithDefaultNil create RedisSimple instance
+func NewRedisSimpleWithDefaultNil(client *redisLib.Client, allowDefaultNil bool) *RedisSimple {
+	c := NewRedisSimpleWithOptions(client, RedisOptions{})
+	c.AllowDefaultNil = allowDefaultNil
+	return c
+}
+
+// NewRedisSimpleWithOptions create RedisSimple instance with options
+func NewRedisSimpleWithOptions(client *redisLib.Client, options RedisOptions) *RedisSimple {
+	redis := NewRedisWithOptions(client, options)
+	return &RedisSimple{
+		Simple: NewSimple(redis),
+		redis:  redis,
+	}
+}
+
+// Redis returns underlying Redis cache
+func (c *RedisSimple) Redis() *Redis {
+	return c.redis
+}
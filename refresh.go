@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"github.com/gouef/standards"
+	"sync"
+	"time"
+)
+
+// RefreshOptions configure Refresher.
+type RefreshOptions struct {
+	// SoftTTL is time for which loaded value is fresh, 0 means value is always fresh.
+	SoftTTL time.Duration
+	// HardTTL is time after which value expires, between SoftTTL and HardTTL stale value is returned
+	// while it is refreshed in background. 0 means value never expires, HardTTL lower than SoftTTL means SoftTTL.
+	HardTTL time.Duration
+	// RefreshAhead refreshes value in background when it is fresh for less than RefreshAhead, 0 means disabled.
+	RefreshAhead time.Duration
+	// Codec serializes values in backends which don't keep Go values (File, Redis), nil means JSONCodec.
+	Codec Codec
+	// OnRefreshError is called when background refresh fails, stale value is kept until HardTTL.
+	OnRefreshError func(key string, err error)
+}
+
+// Refresher loads values of keys into cache and serves stale values while they are refreshed in background
+// (stale-while-revalidate), so readers of expired hot key don't wait for loader at once.
+// Values are stored in envelope with time until they are fresh, so keys managed by Refresher
+// must be read only by Refresher, not directly from cache (e.g. by GetItem or Typed).
+type Refresher[T any] struct {
+	typed   *Typed[refreshEntry[T]]
+	loader  func(key string) (T, error)
+	options RefreshOptions
+	flights flightGroup
+	mu      sync.Mutex
+	// refreshing are keys refreshed in background
+	refreshing map[string]struct{}
+	wg         sync.WaitGroup
+}
+
+// refreshEntry is stored value with time until it is fresh, it is what GetItem(key).Get() of cache returns
+type refreshEntry[T any] struct {
+	Value      T         `json:"value"`
+	FreshUntil time.Time `json:"fresh_until"`
+}
+
+// NewRefresher create Refresher instance for cache, loader loads value of key
+func NewRefresher[T any](cache standards.Cache, loader func(key string) (T, error), options RefreshOptions) *Refresher[T] {
+	if options.Codec == nil {
+		options.Codec = JSONCodec{}
+	}
+	if options.HardTTL > 0 && options.HardTTL < options.SoftTTL {
+		options.HardTTL = options.SoftTTL
+	}
+	return &Refresher[T]{
+		typed:      NewTypedWithCodec[refreshEntry[T]](cache, options.Codec),
+		loader:     loader,
+		options:    options,
+		refreshing: make(map[string]struct{}),
+	}
+}
+
+// Get returns value of key. Missing or expired value is loaded, concurrent misses are loaded only once.
+// Stale value (or fresh value within RefreshAhead) is returned immediately and refreshed in background.
+func (r *Refresher[T]) Get(key string) (T, error) {
+	entry, found, err := r.typed.Get(key)
+	if err != nil || !found {
+		// undecodable value is loaded again
+		return r.load(key)
+	}
+
+	if r.needsRefresh(entry) {
+		r.refreshAsync(key)
+	}
+	return entry.Value, nil
+}
+
+// Refresh loads value of key and stores it, concurrent calls are coalesced
+func (r *Refresher[T]) Refresh(key string) (T, error) {
+	return r.load(key)
+}
+
+// Wait waits for background refreshes, e.g. before shutdown
+func (r *Refresher[T]) Wait() {
+	r.wg.Wait()
+}
+
+func (r *Refresher[T]) load(key string) (T, error) {
+	loaded, err := r.flights.Do(r.typed.Cache(), key, func() (any, error) {
+		start := time.Now()
+		value, err := r.loader(key)
+		if err != nil {
+			return value, err
+		}
+		return value, r.store(key, value, time.Since(start))
+	})
+	value, _ := loaded.(T)
+	return value, err
+}
+
+func (r *Refresher[T]) store(key string, value T, computeTime time.Duration) error {
+	entry := refreshEntry[T]{Value: value}
+	if r.options.SoftTTL > 0 {
+		entry.FreshUntil = time.Now().Add(r.options.SoftTTL)
+	}
+	return r.typed.set(key, entry, r.options.HardTTL, computeTime)
+}
+
+// refreshAsync starts background refresh of key unless it is already refreshed
+func (r *Refresher[T]) refreshAsync(key string) {
+	r.mu.Lock()
+	if _, exists := r.refreshing[key]; exists {
+		r.mu.Unlock()
+		return
+	}
+	r.refreshing[key] = struct{}{}
+	r.wg.Add(1)
+	r.mu.Unlock()
+
+	go func() {
+		defer r.wg.Done()
+		defer func() {
+			r.mu.Lock()
+			delete(r.refreshing, key)
+			r.mu.Unlock()
+		}()
+
+		// value could be refreshed by other reader since it was read
+		if entry, found, err := r.typed.Get(key); err == nil && found && !r.needsRefresh(entry) {
+			return
+		}
+		if _, err := r.load(key); err != nil && r.options.OnRefreshError != nil {
+			r.options.OnRefreshError(key, err)
+		}
+	}()
+}
+
+// needsRefresh reports if entry is stale or fresh for less than RefreshAhead
+func (r *Refresher[T]) needsRefresh(entry refreshEntry[T]) bool {
+	return !entry.FreshUntil.IsZero() && time.Until(entry.FreshUntil) < r.options.RefreshAhead
+}
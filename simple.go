@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"github.com/gouef/standards"
+	"time"
+)
+
+// SimpleCache is simple key/value API of cache (FileSimple, MemorySimple, RedisSimple, Simple).
+type SimpleCache interface {
+	// Get Returns a value from the cache, defaultValue is returned when item doesn't exist.
+	Get(key string, defaultValue any) any
+	// GetMultiply Returns a list of cache items.
+	GetMultiply(keys []string, defaultValue any) []any
+	// Has Determines whether an item is present in the cache.
+	Has(key string) bool
+	// Clear Deletes all cache's keys.
+	Clear() error
+	// Delete Remove an item from the cache.
+	Delete(key string) error
+	// DeleteMultiply Removes multiple items in a single operation.
+	DeleteMultiply(keys ...string) error
+	// Set Persists a cache item.
+	Set(key string, value any, ttl time.Duration) error
+	// SetMultiply Persists a cache items.
+	SetMultiply(values map[string]any, ttl time.Duration) error
+}
+
+// Simple adapts any standards.Cache to SimpleCache, cache has to implement ItemFactory for Set.
+type Simple struct {
+	cache           standards.Cache
+	AllowDefaultNil bool
+}
+
+// manySaver is cache which can save multiple items at once (Redis)
+type manySaver interface {
+	SaveMany(items ...standards.CacheItem) error
+}
+
+// NewSimple create Simple instance with not allowed default value nil
+func NewSimple(cache standards.Cache) *Simple {
+	return NewSimpleWithDefaultNil(cache, false)
+}
+
+// NewSimpleWithDefaultNil create Simple instance
+func NewSimpleWithDefaultNil(cache standards.Cache, allowDefaultNil bool) *Simple {
+	return &Simple{
+		cache:           cache,
+		AllowDefaultNil: allowDefaultNil,
+	}
+}
+
+// Cache returns adapted cache
+func (c *Simple) Cache() standards.Cache {
+	return c.cache
+}
+
+// Get Returns a value from the cache.
+func (c *Simple) Get(key string, defaultValue any) any {
+	item := c.cache.GetItem(key)
+	if item == nil || !item.IsHit() {
+		return defaultValue
+	}
+	return item.Get()
+}
+
+// GetMultiply Returns a list of cache items.
+func (c *Simple) GetMultiply(keys []string, defaultValue any) []any {
+	found := make(map[string]standards.CacheItem, len(keys))
+	for _, item := range c.cache.GetItems(keys...) {
+		if item != nil && item.IsHit() {
+			found[item.GetKey()] = item
+		}
+	}
+
+	result := []any{}
+	for _, key := range keys {
+		var value any = defaultValue
+		if item, exists := found[key]; exists {
+			value = item.Get()
+		}
+
+		if (c.AllowDefaultNil && value == nil) || value != nil {
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
+
+// Has Determines whether an item is present in the cache.
+func (c *Simple) Has(key string) bool {
+	item := c.Get(key, nil)
+
+	if (c.AllowDefaultNil && item == nil) || item != nil {
+		return true
+	}
+
+	return false
+}
+
+// Clear Deletes all cache's keys.
+func (c *Simple) Clear() error {
+	return c.cache.Clear()
+}
+
+// Delete Remove an item from the cache.
+func (c *Simple) Delete(key string) error {
+	return c.cache.DeleteItem(key)
+}
+
+// DeleteMultiply Removes multiple items in a single operation.
+func (c *Simple) DeleteMultiply(keys ...string) error {
+	return c.cache.DeleteItems(keys...)
+}
+
+// Set Persists a cache item, zero ttl means item never expires.
+func (c *Simple) Set(key string, value any, ttl time.Duration) error {
+	item, err := newCacheItem(c.cache, key, value, ttl)
+	if err != nil {
+		return err
+	}
+	return c.cache.Save(item)
+}
+
+// SetMultiply Persists a cache items, zero ttl means items never expire.
+func (c *Simple) SetMultiply(values map[string]any, ttl time.Duration) error {
+	items := make([]standards.CacheItem, 0, len(values))
+	for key, value := range values {
+		item, err := newCacheItem(c.cache, key, value, ttl)
+		if err != nil {
+			return err
+		}
+		items = append(items, item)
+	}
+
+	return saveManyOf(c.cache, items...)
+}
+
+// Purge Removes expired items when cache supports it.
+func (c *Simple) Purge() (int, error) {
+	if purger, ok := c.cache.(Purger); ok {
+		return purger.Purge()
+	}
+	return 0, nil
+}
@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"fmt"
+	"github.com/gouef/standards"
+	"reflect"
+	"sync"
+)
+
+// flights coalesce concurrent loads of the same key in the same cache
+var flights = &flightGroup{}
+
+type flightKey struct {
+	cache standards.Cache
+	key   string
+}
+
+type flightCall struct {
+	wg    sync.WaitGroup
+	value any
+	err   error
+}
+
+// flightGroup runs only one function for the same key at once, other callers wait for its result
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[flightKey]*flightCall
+}
+
+func (g *flightGroup) Do(c standards.Cache, key string, fn func() (any, error)) (any, error) {
+	if !reflect.TypeOf(c).Comparable() {
+		// cache can't be used as map key, so there is nothing to coalesce with
+		return fn()
+	}
+
+	k := flightKey{cache: c, key: key}
+
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[flightKey]*flightCall)
+	}
+	if call, exists := g.calls[k]; exists {
+		g.mu.Unlock()
+		call.wg.Wait()
+		return call.value, call.err
+	}
+
+	call := &flightCall{}
+	call.wg.Add(1)
+	g.calls[k] = call
+	g.mu.Unlock()
+
+	defer func() {
+		// waiters get error when fn panics, panic continues in caller of fn
+		recovered := recover()
+		if recovered != nil {
+			call.value, call.err = nil, fmt.Errorf("cache loader of key \"%s\" panicked: %v", key, recovered)
+		}
+		g.mu.Lock()
+		delete(g.calls, k)
+		g.mu.Unlock()
+		call.wg.Done()
+		if recovered != nil {
+			panic(recovered)
+		}
+	}()
+
+	call.value, call.err = fn()
+	return call.value, call.err
+}
@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"github.com/gouef/standards"
+	"time"
+)
+
+// SlidingItem is item with sliding expiration (time-to-idle): every hit pushes its expiration
+// forward by idle timeout, but never past its max lifetime. It suits session-like data.
+type SlidingItem interface {
+	// ExpiresAfterIdle sets idle timeout of item and expires it after idle, maxLifetime caps expiration
+	// counted from now, 0 means item slides without limit. Idle 0 without maxLifetime means item doesn't slide
+	// and keeps its expiration.
+	ExpiresAfterIdle(idle, maxLifetime time.Duration) standards.CacheItem
+	// GetIdleTimeout returns idle timeout and max expiration of item, idle is 0 when item doesn't slide
+	GetIdleTimeout() (idle time.Duration, maxExpiration time.Time)
+}
+
+// maxExpirationAfter returns max expiration of item with maxLifetime, zero time means unlimited
+func maxExpirationAfter(maxLifetime time.Duration, now time.Time) time.Time {
+	if maxLifetime <= 0 {
+		return time.Time{}
+	}
+	return now.Add(maxLifetime)
+}
+
+// hasIdleExpiration reports if idle timeout or max expiration sets expiration of item, otherwise item keeps its expiration
+func hasIdleExpiration(idle time.Duration, maxExpiration time.Time) bool {
+	return idle > 0 || !maxExpiration.IsZero()
+}
+
+// idleExpiration returns expiration of item with idle timeout hit at now, capped by maxExpiration
+func idleExpiration(idle time.Duration, maxExpiration, now time.Time) time.Time {
+	expiration := now.Add(idle)
+	if !maxExpiration.IsZero() && (idle <= 0 || expiration.After(maxExpiration)) {
+		return maxExpiration
+	}
+	return expiration
+}
+
+// idleTimeoutOf returns idle timeout and max expiration of item, idle is 0 when item doesn't slide
+func idleTimeoutOf(item standards.CacheItem) (time.Duration, time.Time) {
+	if sliding, ok := item.(SlidingItem); ok {
+		return sliding.GetIdleTimeout()
+	}
+	return 0, time.Time{}
+}
+
+// copyIdleTimeout copies idle timeout and max lifetime of item to copied item which supports it
+func copyIdleTimeout(copied, item standards.CacheItem) {
+	idle, maxExpiration := idleTimeoutOf(item)
+	sliding, ok := copied.(SlidingItem)
+	if idle <= 0 || !ok {
+		return
+	}
+
+	var maxLifetime time.Duration
+	if !maxExpiration.IsZero() {
+		maxLifetime = time.Until(maxExpiration)
+	}
+	sliding.ExpiresAfterIdle(idle, maxLifetime)
+}
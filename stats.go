@@ -0,0 +1,172 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// DefaultLatencyBuckets are upper bounds of latency histogram buckets used by StatsCache
+var DefaultLatencyBuckets = []time.Duration{
+	50 * time.Microsecond,
+	100 * time.Microsecond,
+	250 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	2500 * time.Microsecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+}
+
+// Stats is snapshot of cache statistics.
+type Stats struct {
+	Hits        uint64
+	Misses      uint64
+	Sets        uint64
+	Deletes     uint64
+	Expirations uint64
+	Evictions   uint64
+	// GetLatency is latency of GetItem, GetItems and HasItem.
+	GetLatency Histogram
+	// SetLatency is latency of Save and SaveDeferred.
+	SetLatency Histogram
+	// DeleteLatency is latency of DeleteItem and DeleteItems.
+	DeleteLatency Histogram
+}
+
+// Histogram is snapshot of latency histogram.
+type Histogram struct {
+	// Buckets are upper bounds of buckets.
+	Buckets []time.Duration
+	// Counts are counts of observations in buckets (not cumulative), last count is for observations over last bucket.
+	Counts []uint64
+	Count  uint64
+	Sum    time.Duration
+}
+
+// StatsProvider is cache which provides statistics.
+type StatsProvider interface {
+	Stats() Stats
+}
+
+// EvictionCounter is cache which counts items evicted because of limits.
+type EvictionCounter interface {
+	Evictions() uint64
+}
+
+// ExpirationCounter is cache which counts removed expired items.
+type ExpirationCounter interface {
+	Expirations() uint64
+}
+
+// HitRatio returns ratio of hits to all reads, 0 when there were no reads
+func (s Stats) HitRatio() float64 {
+	reads := s.Hits + s.Misses
+	if reads == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(reads)
+}
+
+// Add returns sum of statistics
+func (s Stats) Add(other Stats) Stats {
+	return Stats{
+		Hits:          s.Hits + other.Hits,
+		Misses:        s.Misses + other.Misses,
+		Sets:          s.Sets + other.Sets,
+		Deletes:       s.Deletes + other.Deletes,
+		Expirations:   s.Expirations + other.Expirations,
+		Evictions:     s.Evictions + other.Evictions,
+		GetLatency:    s.GetLatency.Add(other.GetLatency),
+		SetLatency:    s.SetLatency.Add(other.SetLatency),
+		DeleteLatency: s.DeleteLatency.Add(other.DeleteLatency),
+	}
+}
+
+// Mean returns mean of observations, 0 when there are no observations
+func (h Histogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+// Add returns sum of histograms, histograms with different buckets are summed only by Count and Sum
+func (h Histogram) Add(other Histogram) Histogram {
+	if h.Count == 0 && len(h.Counts) == 0 {
+		return other
+	}
+	if other.Count == 0 && len(other.Counts) == 0 {
+		return h
+	}
+
+	sum := Histogram{
+		Buckets: h.Buckets,
+		Count:   h.Count + other.Count,
+		Sum:     h.Sum + other.Sum,
+	}
+	if !sameBuckets(h.Buckets, other.Buckets) {
+		sum.Buckets = nil
+		return sum
+	}
+	sum.Counts = make([]uint64, len(h.Counts))
+	for i := range h.Counts {
+		sum.Counts[i] = h.Counts[i] + other.Counts[i]
+	}
+	return sum
+}
+
+func sameBuckets(a, b []time.Duration) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// latencyHistogram is histogram with atomic counters
+type latencyHistogram struct {
+	buckets []time.Duration
+	counts  []atomic.Uint64
+	count   atomic.Uint64
+	sum     atomic.Int64
+}
+
+func newLatencyHistogram(buckets []time.Duration) *latencyHistogram {
+	return &latencyHistogram{
+		buckets: buckets,
+		counts:  make([]atomic.Uint64, len(buckets)+1),
+	}
+}
+
+func (h *latencyHistogram) observe(d time.Duration) {
+	i := 0
+	for i < len(h.buckets) && d > h.buckets[i] {
+		i++
+	}
+	h.counts[i].Add(1)
+	h.count.Add(1)
+	h.sum.Add(int64(d))
+}
+
+func (h *latencyHistogram) snapshot() Histogram {
+	counts := make([]uint64, len(h.counts))
+	for i := range h.counts {
+		counts[i] = h.counts[i].Load()
+	}
+	return Histogram{
+		Buckets: h.buckets,
+		Counts:  counts,
+		Count:   h.count.Load(),
+		Sum:     time.Duration(h.sum.Load()),
+	}
+}
@@ -0,0 +1,259 @@
+package cache
+
+import (
+	"context"
+	"github.com/gouef/standards"
+	"sync/atomic"
+	"time"
+)
+
+// StatsCache is decorator of any standards.Cache which collects statistics of operations.
+// Evictions and expirations are taken from cache (or cache decorated by it) implementing EvictionCounter and ExpirationCounter.
+type StatsCache struct {
+	cache         standards.Cache
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	sets          atomic.Uint64
+	deletes       atomic.Uint64
+	expirations   atomic.Uint64
+	getLatency    *latencyHistogram
+	setLatency    *latencyHistogram
+	deleteLatency *latencyHistogram
+}
+
+// NewStatsCache create StatsCache decorator of cache with DefaultLatencyBuckets
+func NewStatsCache(cache standards.Cache) *StatsCache {
+	return NewStatsCacheWithBuckets(cache, DefaultLatencyBuckets)
+}
+
+// NewStatsCacheWithBuckets create StatsCache decorator of cache with upper bounds of latency histogram buckets
+func NewStatsCacheWithBuckets(cache standards.Cache, buckets []time.Duration) *StatsCache {
+	return &StatsCache{
+		cache:         cache,
+		getLatency:    newLatencyHistogram(buckets),
+		setLatency:    newLatencyHistogram(buckets),
+		deleteLatency: newLatencyHistogram(buckets),
+	}
+}
+
+// Unwrap returns decorated cache
+func (c *StatsCache) Unwrap() standards.Cache {
+	return c.cache
+}
+
+// Stats returns snapshot of statistics
+func (c *StatsCache) Stats() Stats {
+	stats := Stats{
+		Hits:          c.hits.Load(),
+		Misses:        c.misses.Load(),
+		Sets:          c.sets.Load(),
+		Deletes:       c.deletes.Load(),
+		Expirations:   c.expirations.Load(),
+		GetLatency:    c.getLatency.snapshot(),
+		SetLatency:    c.setLatency.snapshot(),
+		DeleteLatency: c.deleteLatency.snapshot(),
+	}
+	if counter, ok := findCache[ExpirationCounter](c.cache); ok {
+		stats.Expirations = counter.Expirations()
+	}
+	if counter, ok := findCache[EvictionCounter](c.cache); ok {
+		stats.Evictions = counter.Evictions()
+	}
+	return stats
+}
+
+// NewItem create empty item of decorated cache
+func (c *StatsCache) NewItem(key string) standards.CacheItem {
+	return newItemOf(c.cache, key)
+}
+
+func (c *StatsCache) GetItem(key string) standards.CacheItem {
+	return c.GetItemContext(context.Background(), key)
+}
+
+func (c *StatsCache) GetItemContext(ctx context.Context, key string) standards.CacheItem {
+	start := time.Now()
+	item := contextOf(c.cache).GetItemContext(ctx, key)
+	c.getLatency.observe(time.Since(start))
+
+	c.count(item != nil && item.IsHit())
+	return item
+}
+
+// GetItemE returns item of decorated cache, errors are counted as misses, see CheckedReader
+func (c *StatsCache) GetItemE(key string) (standards.CacheItem, error) {
+	start := time.Now()
+	item, err := getItemEOf(c.cache, key)
+	c.getLatency.observe(time.Since(start))
+
+	c.count(err == nil)
+	return item, err
+}
+
+func (c *StatsCache) GetItems(keys ...string) []standards.CacheItem {
+	return c.GetItemsContext(context.Background(), keys...)
+}
+
+func (c *StatsCache) GetItemsContext(ctx context.Context, keys ...string) []standards.CacheItem {
+	start := time.Now()
+	items := contextOf(c.cache).GetItemsContext(ctx, keys...)
+	c.getLatency.observe(time.Since(start))
+
+	hits := 0
+	for _, item := range items {
+		if item != nil && item.IsHit() {
+			hits++
+		}
+	}
+	c.hits.Add(uint64(hits))
+	if len(keys) > hits {
+		c.misses.Add(uint64(len(keys) - hits))
+	}
+	return items
+}
+
+func (c *StatsCache) HasItem(key string) bool {
+	return c.HasItemContext(context.Background(), key)
+}
+
+func (c *StatsCache) HasItemContext(ctx context.Context, key string) bool {
+	start := time.Now()
+	has := contextOf(c.cache).HasItemContext(ctx, key)
+	c.getLatency.observe(time.Since(start))
+
+	c.count(has)
+	return has
+}
+
+// HasItemE checks item of decorated cache, errors are counted as misses, see CheckedReader
+func (c *StatsCache) HasItemE(key string) (bool, error) {
+	start := time.Now()
+	has, err := hasItemEOf(c.cache, key)
+	c.getLatency.observe(time.Since(start))
+
+	c.count(has)
+	return has, err
+}
+
+func (c *StatsCache) Clear() error {
+	return c.ClearContext(context.Background())
+}
+
+func (c *StatsCache) ClearContext(ctx context.Context) error {
+	return contextOf(c.cache).ClearContext(ctx)
+}
+
+// ClearPrefix removes items with keys starting with prefix when decorated cache supports it
+func (c *StatsCache) ClearPrefix(prefix string) error {
+	return clearPrefixOf(c.cache, prefix)
+}
+
+func (c *StatsCache) DeleteItem(key string) error {
+	return c.DeleteItemContext(context.Background(), key)
+}
+
+func (c *StatsCache) DeleteItemContext(ctx context.Context, key string) error {
+	start := time.Now()
+	err := contextOf(c.cache).DeleteItemContext(ctx, key)
+	c.deleteLatency.observe(time.Since(start))
+
+	c.deletes.Add(1)
+	return err
+}
+
+func (c *StatsCache) DeleteItems(keys ...string) error {
+	return c.DeleteItemsContext(context.Background(), keys...)
+}
+
+func (c *StatsCache) DeleteItemsContext(ctx context.Context, keys ...string) error {
+	start := time.Now()
+	err := contextOf(c.cache).DeleteItemsContext(ctx, keys...)
+	c.deleteLatency.observe(time.Since(start))
+
+	c.deletes.Add(uint64(len(keys)))
+	return err
+}
+
+func (c *StatsCache) Save(item standards.CacheItem) error {
+	return c.SaveContext(context.Background(), item)
+}
+
+func (c *StatsCache) SaveContext(ctx context.Context, item standards.CacheItem) error {
+	start := time.Now()
+	err := contextOf(c.cache).SaveContext(ctx, item)
+	c.setLatency.observe(time.Since(start))
+
+	if err == nil {
+		c.sets.Add(1)
+	}
+	return err
+}
+
+// SaveMany saves items at once when decorated cache supports it
+func (c *StatsCache) SaveMany(items ...standards.CacheItem) error {
+	start := time.Now()
+	err := saveManyOf(c.cache, items...)
+	c.setLatency.observe(time.Since(start))
+
+	if err == nil {
+		c.sets.Add(uint64(len(items)))
+	}
+	return err
+}
+
+func (c *StatsCache) SaveDeferred(item standards.CacheItem) error {
+	return c.SaveDeferredContext(context.Background(), item)
+}
+
+func (c *StatsCache) SaveDeferredContext(ctx context.Context, item standards.CacheItem) error {
+	start := time.Now()
+	err := contextOf(c.cache).SaveDeferredContext(ctx, item)
+	c.setLatency.observe(time.Since(start))
+
+	if err == nil {
+		c.sets.Add(1)
+	}
+	return err
+}
+
+func (c *StatsCache) Commit() error {
+	return c.CommitContext(context.Background())
+}
+
+func (c *StatsCache) CommitContext(ctx context.Context) error {
+	return contextOf(c.cache).CommitContext(ctx)
+}
+
+// Discard drops pending items when decorated cache supports it
+func (c *StatsCache) Discard() error {
+	return discardOf(c.cache)
+}
+
+// InvalidateTags removes items with tags when decorated cache supports tags
+func (c *StatsCache) InvalidateTags(tags ...string) error {
+	return invalidateTagsOf(c.cache, tags...)
+}
+
+// Purge removes expired items when decorated cache supports it, purged items are counted as expirations
+func (c *StatsCache) Purge() (int, error) {
+	purged, err := purgeOf(c.cache)
+	c.expirations.Add(uint64(purged))
+	return purged, err
+}
+
+// count counts read as hit or miss
+func (c *StatsCache) count(hit bool) {
+	if hit {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+}
+
+// withStats wraps cache by StatsCache when it doesn't provide statistics yet
+func withStats(cache standards.Cache) standards.Cache {
+	if _, ok := findCache[StatsProvider](cache); ok {
+		return cache
+	}
+	return NewStatsCache(cache)
+}
@@ -1,10 +1,14 @@
 package cache
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/gouef/standards"
 	redisLib "github.com/redis/go-redis/v9"
+	"io"
+	"reflect"
+	"sort"
 	"sync"
 )
 
@@ -16,18 +20,35 @@ var (
 )
 
 type Storage struct {
-	Storages map[string]standards.Cache
+	// caches are caches by name, accessed by Get, Names and Range
+	caches map[string]standards.Cache
+	// mu guards caches and configuration of Storage
+	mu sync.RWMutex
+	// middlewares decorate every added cache
+	middlewares []Middleware
+	// log configures logging of every added cache which supports it
+	log *LogOptions
 }
 
 // NewStorage create new instance of Storage
 func NewStorage() *Storage {
+	s := newStorage()
+	publishStorage(s)
+	return s
+}
+
+// newStorage create new instance of Storage without making it global
+func newStorage() *Storage {
+	return &Storage{
+		caches: make(map[string]standards.Cache),
+	}
+}
+
+// publishStorage makes s global storage returned by GetStorage
+func publishStorage(s *Storage) {
 	mu.Lock()
 	defer mu.Unlock()
-	s := &Storage{
-		Storages: make(map[string]standards.Cache),
-	}
 	storage = s
-	return s
 }
 
 // GetStorage get storage (for global usages)
@@ -37,42 +58,280 @@ func GetStorage() *Storage {
 	return storage
 }
 
-// Add add cache instance to list
-func (s *Storage) Add(name string, cache standards.Cache) (standards.Cache, error) {
-	v, exists := s.Get(name)
+// Add add cache instance to list, cache is decorated by middlewares of Use and mws (mws are inner)
+func (s *Storage) Add(name string, cache standards.Cache, mws ...Middleware) (standards.Cache, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, exists := s.caches[name]
 
 	if exists {
 		return v, errors.New(fmt.Sprintf("Storage with name \"%s\" already exists.", name))
 	}
 
-	s.Storages[name] = cache
+	cache = s.decorate(name, cache, mws)
+	s.caches[name] = cache
+	return cache, nil
+}
+
+// Replace add cache instance to list in place of cache with same name, replaced cache is closed (see Close)
+// unless cache is the same instance or decorates it. Cache is decorated same as by Add.
+func (s *Storage) Replace(name string, cache standards.Cache, mws ...Middleware) (standards.Cache, error) {
+	s.mu.Lock()
+	previous, exists := s.caches[name]
+	cache = s.decorate(name, cache, mws)
+	s.caches[name] = cache
+	s.mu.Unlock()
+
+	if !exists {
+		return cache, nil
+	}
+	closer, ok := findCache[io.Closer](previous)
+	if !ok || decorates(cache, closer) {
+		return cache, nil
+	}
+	if err := closer.Close(); err != nil {
+		return cache, fmt.Errorf("cache \"%s\": %w", name, err)
+	}
 	return cache, nil
 }
 
+// decorates reports if cache is target or one of decorators of cache wraps target
+func decorates(cache standards.Cache, target any) bool {
+	for cache != nil {
+		if reflect.TypeOf(cache).Comparable() && any(cache) == target {
+			return true
+		}
+		unwrapper, ok := cache.(Unwrapper)
+		if !ok {
+			break
+		}
+		cache = unwrapper.Unwrap()
+	}
+	return false
+}
+
+// Remove removes cache from list and returns it, removed cache isn't closed
+func (s *Storage) Remove(name string) (cache standards.Cache, exists bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cache, exists = s.caches[name]
+	delete(s.caches, name)
+	return
+}
+
+// Names returns sorted names of caches
+func (s *Storage) Names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.caches))
+	for name := range s.caches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Range calls fn for every cache sorted by name until fn returns false,
+// fn can use Storage (e.g. Remove cache)
+func (s *Storage) Range(fn func(name string, cache standards.Cache) bool) {
+	caches := s.snapshot()
+	for _, name := range s.Names() {
+		cache, exists := caches[name]
+		if !exists {
+			continue
+		}
+		if !fn(name, cache) {
+			return
+		}
+	}
+}
+
+// Close closes all caches implementing io.Closer (also decorated ones) and removes all caches from list
+func (s *Storage) Close() error {
+	s.mu.Lock()
+	caches := s.caches
+	s.caches = make(map[string]standards.Cache)
+	s.mu.Unlock()
+
+	var errs []error
+	for name, cache := range caches {
+		if err := closeCache(cache); err != nil {
+			errs = append(errs, fmt.Errorf("cache \"%s\": %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Ping checks health of all caches, see PingContext
+func (s *Storage) Ping() map[string]error {
+	return s.PingContext(context.Background())
+}
+
+// PingContext checks health of all caches implementing Pinger (also decorated ones),
+// result contains every cache by name, nil error means cache is healthy
+func (s *Storage) PingContext(ctx context.Context) map[string]error {
+	result := make(map[string]error)
+	for name, cache := range s.snapshot() {
+		result[name] = nil
+		if pinger, ok := findCache[Pinger](cache); ok {
+			result[name] = pinger.PingContext(ctx)
+		}
+	}
+	return result
+}
+
+// snapshot returns copy of list, so caches can be used without holding lock
+func (s *Storage) snapshot() map[string]standards.Cache {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	caches := make(map[string]standards.Cache, len(s.caches))
+	for name, cache := range s.caches {
+		caches[name] = cache
+	}
+	return caches
+}
+
+// decorate wraps cache by StatsCache (unless it provides statistics) and middlewares and configures its logging,
+// caller must hold lock
+func (s *Storage) decorate(name string, cache standards.Cache, mws []Middleware) standards.Cache {
+	cache = Wrap(Wrap(withStats(cache), mws...), s.middlewares...)
+	if s.log != nil {
+		s.setLogOptions(name, cache)
+	}
+	return cache
+}
+
+// SetLogOptions configure logging of all caches (also caches added later) which support it,
+// name of cache is logged as "cache" attribute
+func (s *Storage) SetLogOptions(options LogOptions) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.log = &options
+	for name, cache := range s.caches {
+		s.setLogOptions(name, cache)
+	}
+}
+
+func (s *Storage) setLogOptions(name string, cache standards.Cache) {
+	configurable, ok := findCache[LogConfigurable](cache)
+	if !ok {
+		return
+	}
+	options := *s.log
+	options.Name = name
+	configurable.SetLogOptions(options)
+}
+
+// Use adds middlewares which decorate every cache added later, first middleware is outermost
+func (s *Storage) Use(mws ...Middleware) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.middlewares = append(s.middlewares, mws...)
+}
+
+// EnableStats does nothing, statistics of every cache are collected since it is added
+//
+// Deprecated: every added cache is wrapped by StatsCache.
+func (s *Storage) EnableStats() {}
+
 // Get return cache instance
 func (s *Storage) Get(name string) (cache standards.Cache, exists bool) {
-	cache, exists = s.Storages[name]
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	cache, exists = s.caches[name]
 	return
 }
 
+// InvalidateTags removes items with at least one of tags from all caches which support tags
+func (s *Storage) InvalidateTags(tags ...string) error {
+	var errs []error
+	for name, cache := range s.snapshot() {
+		invalidator, ok := cache.(TagInvalidator)
+		if !ok {
+			continue
+		}
+		if err := invalidator.InvalidateTags(tags...); err != nil {
+			errs = append(errs, fmt.Errorf("cache \"%s\": %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // AddFile create File cache instance and add it to list
-func (s *Storage) AddFile(name, dir string) (standards.Cache, error) {
+func (s *Storage) AddFile(name, dir string, mws ...Middleware) (standards.Cache, error) {
 	fileCache, err := NewFile(dir)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.Add(name, fileCache)
+	return s.Add(name, fileCache, mws...)
+}
+
+// AddFileWithOptions create File cache instance with options and add it to list
+func (s *Storage) AddFileWithOptions(name, dir string, options FileOptions, mws ...Middleware) (standards.Cache, error) {
+	fileCache, err := NewFileWithOptions(dir, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Add(name, fileCache, mws...)
 }
 
 // AddMemory create Memory cache instance and add it to list
-func (s *Storage) AddMemory(name string) (standards.Cache, error) {
+func (s *Storage) AddMemory(name string, mws ...Middleware) (standards.Cache, error) {
 	memoryCache := NewMemory()
-	return s.Add(name, memoryCache)
+	return s.Add(name, memoryCache, mws...)
+}
+
+// AddMemoryWithOptions create Memory cache instance with limits and add it to list
+func (s *Storage) AddMemoryWithOptions(name string, options MemoryOptions, mws ...Middleware) (standards.Cache, error) {
+	memoryCache := NewMemoryWithOptions(options)
+	return s.Add(name, memoryCache, mws...)
 }
 
 // AddRedis create Redis cache instance and add it to list
-func (s *Storage) AddRedis(name string, client *redisLib.Client) (standards.Cache, error) {
+func (s *Storage) AddRedis(name string, client *redisLib.Client, mws ...Middleware) (standards.Cache, error) {
 	redisCache := NewRedis(client)
-	return s.Add(name, redisCache)
+	return s.Add(name, redisCache, mws...)
+}
+
+// AddRedisWithOptions create Redis cache instance with options and add it to list
+func (s *Storage) AddRedisWithOptions(name string, client *redisLib.Client, options RedisOptions, mws ...Middleware) (standards.Cache, error) {
+	redisCache := NewRedisWithOptions(client, options)
+	return s.Add(name, redisCache, mws...)
+}
+
+// AddChain create Chain cache instance with tiers and add it to list
+func (s *Storage) AddChain(name string, tiers ...standards.Cache) (standards.Cache, error) {
+	return s.Add(name, NewChain(tiers...))
+}
+
+// AddChainWithOptions create Chain cache instance with options and tiers and add it to list
+func (s *Storage) AddChainWithOptions(name string, options ChainOptions, tiers ...standards.Cache) (standards.Cache, error) {
+	return s.Add(name, NewChainWithOptions(options, tiers...))
+}
+
+// Stats returns statistics of caches which provide them (e.g. wrapped by StatsCache) by name
+func (s *Storage) Stats() map[string]Stats {
+	stats := make(map[string]Stats)
+	for name, cache := range s.snapshot() {
+		if provider, ok := findCache[StatsProvider](cache); ok {
+			stats[name] = provider.Stats()
+		}
+	}
+	return stats
+}
+
+// TotalStats returns sum of statistics of all caches which provide them
+func (s *Storage) TotalStats() Stats {
+	var total Stats
+	for _, stats := range s.Stats() {
+		total = total.Add(stats)
+	}
+	return total
 }
@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"github.com/gouef/standards"
+	"slices"
+)
+
+// TaggedItem is cache item which can carry tags.
+type TaggedItem interface {
+	standards.CacheItem
+	// Tag adds tags to item
+	Tag(tags ...string) standards.CacheItem
+	// GetTags returns tags of item
+	GetTags() []string
+}
+
+// TagInvalidator is cache which can remove all items with given tags.
+type TagInvalidator interface {
+	InvalidateTags(tags ...string) error
+}
+
+// appendTags returns tags with new tags which are not present yet
+func appendTags(tags []string, newTags ...string) []string {
+	for _, tag := range newTags {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// hasAnyTag reports if tags contain at least one of wanted tags
+func hasAnyTag(tags []string, wanted []string) bool {
+	for _, tag := range wanted {
+		if slices.Contains(tags, tag) {
+			return true
+		}
+	}
+	return false
+}
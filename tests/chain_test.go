@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"github.com/go-redis/redismock/v9"
+	"github.com/gouef/cache"
+	"github.com/gouef/standards"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestChain(t *testing.T) {
+	t.Run("Read through and back-fill", func(t *testing.T) {
+		l1 := cache.NewMemory()
+		l2, err := cache.NewFileWithOptions(t.TempDir(), cache.FileOptions{})
+		assert.NoError(t, err)
+		chain := cache.NewChainWithOptions(cache.ChainOptions{BackfillTTL: time.Minute}, l1, l2)
+		assert.Equal(t, []standards.Cache{l1, l2}, chain.Tiers())
+
+		item, _ := cache.NewFileItem("key").Set("data", standards.KeepTTL)
+		assert.NoError(t, l2.Save(item))
+		assert.False(t, l1.HasItem("key"))
+
+		found := chain.GetItem("key")
+		assert.NotNil(t, found)
+		assert.Equal(t, "data", found.Get())
+
+		backfilled := l1.GetItem("key")
+		assert.NotNil(t, backfilled)
+		assert.Equal(t, "data", backfilled.Get())
+		expiration, ok := backfilled.(cache.ExpiringItem).GetExpiration()
+		assert.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), expiration, time.Second)
+
+		assert.Nil(t, chain.GetItem("non-exists"))
+		assert.False(t, chain.HasItem("non-exists"))
+		assert.Len(t, chain.GetItems("key", "non-exists"), 1)
+	})
+
+	t.Run("Back-fill keeps shorter TTL", func(t *testing.T) {
+		l1 := cache.NewMemory()
+		l2 := cache.NewMemory()
+		chain := cache.NewChainWithOptions(cache.ChainOptions{BackfillTTL: time.Hour}, l1, l2)
+
+		item, _ := cache.NewMemoryItem("key").Set("data", time.Minute)
+		assert.NoError(t, l2.Save(item))
+		assert.True(t, chain.HasItem("key"))
+
+		expiration, ok := l1.GetItem("key").(cache.ExpiringItem).GetExpiration()
+		assert.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), expiration, time.Second)
+	})
+
+	t.Run("Write and delete through all tiers", func(t *testing.T) {
+		l1 := cache.NewMemory()
+		l2, err := cache.NewFileWithOptions(t.TempDir(), cache.FileOptions{})
+		assert.NoError(t, err)
+		chain := cache.NewChain(l1, l2)
+
+		item := chain.NewItem("key")
+		assert.IsType(t, &cache.MemoryItem{}, item)
+		item.Set("data", standards.KeepTTL)
+		item.(cache.TaggedItem).Tag("tag")
+		assert.NoError(t, chain.Save(item))
+		assert.True(t, l1.HasItem("key"))
+		assert.True(t, l2.HasItem("key"))
+		assert.Equal(t, []string{"tag"}, l2.GetItem("key").(cache.TaggedItem).GetTags())
+
+		assert.NoError(t, chain.InvalidateTags("tag"))
+		assert.False(t, l1.HasItem("key"))
+		assert.False(t, l2.HasItem("key"))
+
+		assert.NoError(t, chain.Save(item))
+		assert.NoError(t, chain.DeleteItem("key"))
+		assert.False(t, l1.HasItem("key"))
+		assert.False(t, l2.HasItem("key"))
+		assert.NoError(t, chain.DeleteItem("key"))
+
+		assert.NoError(t, chain.Save(item))
+		assert.NoError(t, chain.DeleteItems("key"))
+		assert.False(t, chain.HasItem("key"))
+
+		assert.NoError(t, chain.SaveDeferred(item))
+		assert.True(t, chain.HasItem("key"))
+		assert.NoError(t, chain.Discard())
+		assert.False(t, chain.HasItem("key"))
+
+		assert.NoError(t, chain.SaveDeferred(item))
+		assert.NoError(t, chain.Commit())
+		assert.True(t, l2.HasItem("key"))
+
+		expired, _ := cache.NewMemoryItem("key").Set("data", time.Minute)
+		expired.ExpiresAt(time.Now().Add(-time.Minute))
+		assert.NoError(t, chain.Save(expired))
+		assert.False(t, l2.HasItem("key"))
+
+		assert.NoError(t, chain.Save(item))
+		purged, err := chain.Purge()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, purged)
+
+		assert.NoError(t, chain.Clear())
+		assert.False(t, l1.HasItem("key"))
+		assert.False(t, l2.HasItem("key"))
+	})
+
+	t.Run("Memory in front of Redis", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+		l1 := cache.NewMemory()
+		l2 := cache.NewRedisWithOptions(db, cache.RedisOptions{})
+		chain := cache.NewChainWithOptions(cache.ChainOptions{BackfillTTL: time.Minute}, l1, l2)
+
+		mock.ExpectGet("key").SetVal("data")
+		assert.Equal(t, "data", chain.GetItem("key").Get())
+		assert.Equal(t, "data", chain.GetItem("key").Get())
+		assert.True(t, l1.HasItem("key"))
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Back-fill of unknown TTL is capped by default", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+		l1 := cache.NewMemory()
+		chain := cache.NewChain(l1, cache.NewRedis(db))
+
+		mock.ExpectGet("key").SetVal("data")
+		assert.Equal(t, "data", chain.GetItem("key").Get())
+
+		item := l1.GetItem("key")
+		assert.NotNil(t, item)
+		expiration, ok := item.(cache.ExpiringItem).GetExpiration()
+		assert.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(cache.CHAIN_BACKFILL_TTL), expiration, time.Second)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Storage", func(t *testing.T) {
+		s := cache.NewStorage()
+		c, err := s.AddChain("chain", cache.NewMemory(), cache.NewMemory())
+		assert.NoError(t, err)
+		assert.IsType(t, &cache.Chain{}, cache.UnwrapCache(c))
+
+		c, err = s.AddChainWithOptions("chain 2", cache.ChainOptions{BackfillTTL: time.Minute}, cache.NewMemory())
+		assert.NoError(t, err)
+		assert.IsType(t, &cache.Chain{}, cache.UnwrapCache(c))
+	})
+}
@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"encoding/gob"
+	"github.com/go-redis/redismock/v9"
+	"github.com/gouef/cache"
+	"github.com/gouef/standards"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type codecPoint struct {
+	X, Y int
+}
+
+func init() {
+	gob.Register(codecPoint{})
+}
+
+func TestCodec(t *testing.T) {
+	t.Run("JSON", func(t *testing.T) {
+		codec := cache.JSONCodec{}
+		data, err := codec.Marshal(codecPoint{X: 1, Y: 2})
+		assert.NoError(t, err)
+
+		var point codecPoint
+		assert.NoError(t, codec.Unmarshal(data, &point))
+		assert.Equal(t, codecPoint{X: 1, Y: 2}, point)
+	})
+
+	t.Run("Gob", func(t *testing.T) {
+		codec := cache.GobCodec{}
+		data, err := codec.Marshal(codecPoint{X: 1, Y: 2})
+		assert.NoError(t, err)
+
+		var value any
+		assert.NoError(t, codec.Unmarshal(data, &value))
+		assert.Equal(t, codecPoint{X: 1, Y: 2}, value)
+
+		var point codecPoint
+		assert.NoError(t, codec.Unmarshal(data, &point))
+		assert.Equal(t, codecPoint{X: 1, Y: 2}, point)
+
+		var number int
+		assert.Error(t, codec.Unmarshal(data, &number))
+		assert.Error(t, codec.Unmarshal(data, point))
+		assert.Error(t, codec.Unmarshal([]byte("invalid"), &value))
+
+		data, err = codec.Marshal(nil)
+		assert.NoError(t, err)
+		point = codecPoint{X: 1}
+		assert.NoError(t, codec.Unmarshal(data, &point))
+		assert.Equal(t, codecPoint{}, point)
+
+		_, err = codec.Marshal(make(chan int))
+		assert.Error(t, err)
+	})
+
+	t.Run("Raw", func(t *testing.T) {
+		codec := cache.RawCodec{}
+		data, err := codec.Marshal([]byte{0, 1, 2})
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{0, 1, 2}, data)
+
+		data, err = codec.Marshal("text")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("text"), data)
+
+		_, err = codec.Marshal(1)
+		assert.Error(t, err)
+
+		var blob []byte
+		assert.NoError(t, codec.Unmarshal(data, &blob))
+		assert.Equal(t, []byte("text"), blob)
+
+		var text string
+		assert.NoError(t, codec.Unmarshal(data, &text))
+		assert.Equal(t, "text", text)
+
+		var value any
+		assert.NoError(t, codec.Unmarshal(data, &value))
+		assert.Equal(t, []byte("text"), value)
+
+		var number int
+		assert.Error(t, codec.Unmarshal(data, &number))
+	})
+}
+
+func TestFile_Codec(t *testing.T) {
+	t.Run("Gob keeps type", func(t *testing.T) {
+		c, err := cache.NewFileWithOptions(t.TempDir(), cache.FileOptions{Codec: cache.GobCodec{}})
+		assert.NoError(t, err)
+
+		item := cache.NewFileItem("point")
+		item.Set(codecPoint{X: 1, Y: 2}, standards.KeepTTL)
+		item.ExpiresAfter(time.Minute)
+		assert.NoError(t, c.Save(item))
+
+		cached := c.GetItem("point")
+		assert.NotNil(t, cached)
+		assert.Equal(t, codecPoint{X: 1, Y: 2}, cached.Get())
+
+		purged, err := c.Purge()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, purged)
+	})
+
+	t.Run("Raw", func(t *testing.T) {
+		c, err := cache.NewFileWithOptions(t.TempDir(), cache.FileOptions{Codec: cache.RawCodec{}})
+		assert.NoError(t, err)
+
+		item := cache.NewFileItem("blob")
+		item.Set([]byte{0, 255}, standards.KeepTTL)
+		assert.NoError(t, c.Save(item))
+		assert.Equal(t, []byte{0, 255}, c.GetItem("blob").Get())
+
+		item = cache.NewFileItem("invalid")
+		item.Set(1, standards.KeepTTL)
+		assert.Error(t, c.Save(item))
+	})
+
+	t.Run("FileSimple", func(t *testing.T) {
+		c, err := cache.NewFileSimpleWithOptions(t.TempDir(), cache.FileOptions{Codec: cache.GobCodec{}})
+		assert.NoError(t, err)
+
+		assert.NoError(t, c.Set("point", codecPoint{X: 3}, standards.KeepTTL))
+		assert.NoError(t, c.SetMultiply(map[string]any{"number": 7}, time.Minute))
+		assert.Equal(t, codecPoint{X: 3}, c.Get("point", nil))
+		assert.Equal(t, 7, c.Get("number", nil))
+		assert.Error(t, c.Set("chan", make(chan int), standards.KeepTTL))
+	})
+}
+
+func TestRedis_Codec(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	r := cache.NewRedisWithOptions(db, cache.RedisOptions{Codec: cache.RawCodec{}})
+
+	item, _ := cache.NewRedisItem("blob").Set([]byte{0, 255}, standards.KeepTTL)
+	mock.ExpectSet("blob", []byte{0, 255}, 0).SetVal("OK")
+	assert.NoError(t, r.Save(item))
+
+	mock.ExpectGet("blob").SetVal(string([]byte{0, 255}))
+	assert.Equal(t, []byte{0, 255}, r.GetItem("blob").Get())
+
+	item, _ = cache.NewRedisItem("invalid").Set(1, standards.KeepTTL)
+	assert.Error(t, r.Save(item))
+
+	gobRedis := cache.NewRedisWithOptions(db, cache.RedisOptions{Codec: cache.GobCodec{}})
+	mock.ExpectGet("corrupted").SetVal("corrupted")
+	assert.Nil(t, gobRedis.GetItem("corrupted"))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
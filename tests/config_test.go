@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"github.com/gouef/cache"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpen(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "cache_config_test")
+	_ = os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+
+	memory, err := cache.Open("memory://?max_entries=2&policy=lfu")
+	assert.NoError(t, err)
+	assert.IsType(t, &cache.Memory{}, memory)
+
+	file, err := cache.Open("file://" + dir + "?shard=-1&codec=gob")
+	assert.NoError(t, err)
+	assert.Equal(t, dir, file.(*cache.File).Dir)
+	assert.Equal(t, -1, file.(*cache.File).ShardDepth)
+	assert.Equal(t, cache.GobCodec{}, file.(*cache.File).Codec)
+
+	redis, err := cache.Open("redis://localhost:6379/2?prefix=app:&codec=json&dial_timeout=1s")
+	assert.NoError(t, err)
+	assert.Equal(t, "redis", cache.BackendName(redis))
+
+	for _, dsn := range []string{
+		"",
+		"memcached://localhost",
+		"memory://?max_entries=many",
+		"memory://?max_bytes=-1",
+		"memory://?policy=mru",
+		"memory://?cleanup=soon",
+		"memory://?unknown=1",
+		"file://",
+		"file://" + dir + "?codec=xml",
+		"redis://localhost:6379/2?unknown=1",
+		"chain://?tiers=a",
+	} {
+		_, err := cache.Open(dsn)
+		assert.ErrorIs(t, err, cache.ErrInvalidConfig, dsn)
+	}
+}
+
+func TestStorage_AddConfig(t *testing.T) {
+	config, err := cache.ParseConfig([]byte(`
+caches:
+  users: chain://?tiers=local,shared&backfill_ttl=1m
+  local: memory://?max_entries=100
+  shared: memory://
+`))
+	assert.NoError(t, err)
+
+	s, err := cache.NewStorageFromConfig(config)
+	assert.NoError(t, err)
+	users, exists := s.Get("users")
+	assert.True(t, exists)
+	assert.Len(t, cache.UnwrapCache(users).(*cache.Chain).Tiers(), 2)
+
+	_, err = s.AddDSN("sessions", "chain://?tiers=shared")
+	assert.NoError(t, err)
+	_, err = s.AddDSN("sessions", "memory://")
+	assert.Error(t, err)
+	_, err = s.AddDSN("broken", "memory://?max_entries=x")
+	assert.ErrorIs(t, err, cache.ErrInvalidConfig)
+	assert.ErrorContains(t, err, `cache "broken"`)
+
+	json, err := cache.ParseConfig([]byte(`{"caches": {"a": "memory://?policy=bad", "b": "chain://?tiers=missing", "c": "memory://"}}`))
+	assert.NoError(t, err)
+	err = s.AddConfig(json)
+	assert.ErrorIs(t, err, cache.ErrInvalidConfig)
+	assert.ErrorContains(t, err, `cache "a"`)
+	assert.ErrorContains(t, err, `cache "b": unknown cache "missing"`)
+	_, exists = s.Get("c")
+	assert.False(t, exists, "nothing is added when config is invalid")
+
+	err = s.AddConfig(cache.Config{Caches: map[string]string{"users": "memory://"}})
+	assert.ErrorContains(t, err, `cache "users"`)
+
+	_, err = cache.ParseConfig([]byte("caches: ["))
+	assert.ErrorIs(t, err, cache.ErrInvalidConfig)
+
+	assert.Same(t, s, cache.GetStorage())
+	failed, err := cache.NewStorageFromConfig(json)
+	assert.ErrorIs(t, err, cache.ErrInvalidConfig)
+	assert.Nil(t, failed)
+	assert.Same(t, s, cache.GetStorage(), "invalid config doesn't replace global storage")
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(os.TempDir(), "cache_config_test.yaml")
+	defer os.Remove(path)
+	assert.NoError(t, os.WriteFile(path, []byte("caches:\n  local: memory://\n"), 0644))
+
+	config, err := cache.LoadConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"local": "memory://"}, config.Caches)
+
+	_, err = cache.LoadConfig(path + ".missing")
+	assert.Error(t, err)
+
+	t.Setenv("TEST_CACHE_USERS", "memory://?max_entries=10")
+	assert.Equal(t, map[string]string{"users": "memory://?max_entries=10"}, cache.ConfigFromEnv("TEST_CACHE_").Caches)
+
+	t.Setenv("CACHE_DIR", "/tmp/cache")
+	t.Setenv("CACHE_DSN_SESSIONS", "memory://")
+	assert.Equal(t, map[string]string{"sessions": "memory://"}, cache.ConfigFromEnv("").Caches)
+}
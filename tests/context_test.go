@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"context"
+	"github.com/go-redis/redismock/v9"
+	"github.com/gouef/cache"
+	"github.com/gouef/standards"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func testContextCache(t *testing.T, c cache.ContextCache, newItem func(key string) standards.CacheItem) {
+	ctx := context.Background()
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	item, _ := newItem("a").Set("data", standards.KeepTTL)
+	assert.ErrorIs(t, c.SaveContext(canceled, item), context.Canceled)
+	assert.ErrorIs(t, c.SaveDeferredContext(canceled, item), context.Canceled)
+	assert.NoError(t, c.SaveContext(ctx, item))
+
+	item, _ = newItem("b").Set("data", standards.KeepTTL)
+	assert.NoError(t, c.SaveDeferredContext(ctx, item))
+	assert.ErrorIs(t, c.CommitContext(canceled), context.Canceled)
+	assert.NoError(t, c.CommitContext(ctx))
+
+	assert.Nil(t, c.GetItemContext(canceled, "a"))
+	assert.Empty(t, c.GetItemsContext(canceled, "a", "b"))
+	assert.False(t, c.HasItemContext(canceled, "a"))
+
+	assert.NotNil(t, c.GetItemContext(ctx, "a"))
+	assert.Len(t, c.GetItemsContext(ctx, "a", "b"), 2)
+	assert.True(t, c.HasItemContext(ctx, "a"))
+
+	assert.ErrorIs(t, c.DeleteItemContext(canceled, "a"), context.Canceled)
+	assert.ErrorIs(t, c.DeleteItemsContext(canceled, "a", "b"), context.Canceled)
+	assert.ErrorIs(t, c.ClearContext(canceled), context.Canceled)
+	assert.True(t, c.HasItemContext(ctx, "a"))
+
+	assert.NoError(t, c.DeleteItemContext(ctx, "a"))
+	assert.NoError(t, c.DeleteItemsContext(ctx, "b"))
+	assert.False(t, c.HasItemContext(ctx, "a"))
+	assert.NoError(t, c.ClearContext(ctx))
+}
+
+func TestContextCache(t *testing.T) {
+	t.Run("Memory", func(t *testing.T) {
+		testContextCache(t, cache.NewMemory(), func(key string) standards.CacheItem {
+			return cache.NewMemoryItem(key)
+		})
+	})
+
+	t.Run("File", func(t *testing.T) {
+		c, err := cache.NewFile(t.TempDir())
+		assert.NoError(t, err)
+		testContextCache(t, c.(cache.ContextCache), func(key string) standards.CacheItem {
+			return cache.NewFileItem(key)
+		})
+	})
+
+	t.Run("Redis", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+		r := cache.NewRedis(db).(cache.ContextCache)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		item, _ := cache.NewRedisItem("a").Set("data", standards.KeepTTL)
+		mock.ExpectSet("a", "data", 0).SetVal("OK")
+		assert.NoError(t, r.SaveContext(ctx, item))
+
+		mock.ExpectGet("a").SetVal("data")
+		assert.Equal(t, "data", r.GetItemContext(ctx, "a").Get())
+
+		mock.ExpectDel("a").SetVal(1)
+		assert.NoError(t, r.DeleteItemContext(ctx, "a"))
+
+		canceled, cancel := context.WithCancel(context.Background())
+		cancel()
+		mock.ExpectGet("a").SetErr(context.Canceled)
+		assert.Nil(t, r.GetItemContext(canceled, "a"))
+		assert.ErrorIs(t, r.CommitContext(canceled), context.Canceled)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFileSimple_Context(t *testing.T) {
+	c, err := cache.NewFileSimple(t.TempDir())
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.ErrorIs(t, c.SetContext(canceled, "a", "data", standards.KeepTTL), context.Canceled)
+	assert.ErrorIs(t, c.SetMultiplyContext(canceled, map[string]any{"b": "data"}, time.Minute), context.Canceled)
+	assert.NoError(t, c.SetContext(ctx, "a", "data", standards.KeepTTL))
+	assert.NoError(t, c.SetMultiplyContext(ctx, map[string]any{"b": "data"}, time.Minute))
+	assert.Error(t, c.SetMultiplyContext(ctx, map[string]any{"c": make(chan int)}, time.Minute))
+
+	assert.Equal(t, "default", c.GetContext(canceled, "a", "default"))
+	assert.Empty(t, c.GetMultiplyContext(canceled, []string{"a", "b"}, nil))
+	assert.False(t, c.HasContext(canceled, "a"))
+
+	assert.Equal(t, "data", c.GetContext(ctx, "a", nil))
+	assert.Len(t, c.GetMultiplyContext(ctx, []string{"a", "b"}, nil), 2)
+	assert.True(t, c.HasContext(ctx, "a"))
+
+	assert.ErrorIs(t, c.DeleteContext(canceled, "a"), context.Canceled)
+	assert.ErrorIs(t, c.DeleteMultiplyContext(canceled, "a", "b"), context.Canceled)
+	assert.ErrorIs(t, c.ClearContext(canceled), context.Canceled)
+
+	assert.NoError(t, c.DeleteContext(ctx, "a"))
+	assert.NoError(t, c.DeleteMultiplyContext(ctx, "b"))
+	assert.False(t, c.HasContext(ctx, "b"))
+	assert.NoError(t, c.ClearContext(ctx))
+}
@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"errors"
+	"github.com/go-redis/redismock/v9"
+	"github.com/gouef/cache"
+	"github.com/gouef/standards"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDeferred(t *testing.T) {
+	t.Run("Memory", func(t *testing.T) {
+		c := cache.NewMemory()
+
+		item, _ := cache.NewMemoryItem("a").Set("data a", standards.KeepTTL)
+		assert.NoError(t, c.SaveDeferred(item))
+		assert.Equal(t, 0, c.Len())
+		assert.True(t, c.HasItem("a"))
+		assert.Equal(t, "data a", c.GetItem("a").Get())
+
+		assert.NoError(t, c.Commit())
+		assert.Equal(t, 1, c.Len())
+		assert.True(t, c.HasItem("a"))
+
+		item, _ = cache.NewMemoryItem("b").Set("data b", standards.KeepTTL)
+		assert.NoError(t, c.SaveDeferred(item))
+		assert.NoError(t, c.Discard())
+		assert.NoError(t, c.Commit())
+		assert.False(t, c.HasItem("b"))
+
+		assert.NoError(t, c.SaveDeferred(item))
+		assert.NoError(t, c.DeleteItem("b"))
+		assert.NoError(t, c.Commit())
+		assert.False(t, c.HasItem("b"))
+
+		assert.Error(t, c.SaveDeferred(cache.NewFileItem("c")))
+	})
+
+	t.Run("File", func(t *testing.T) {
+		c, err := cache.NewFileWithOptions(t.TempDir(), cache.FileOptions{})
+		assert.NoError(t, err)
+
+		item, _ := cache.NewFileItem("a").Set("data a", standards.KeepTTL)
+		assert.NoError(t, c.SaveDeferred(item))
+		assert.True(t, c.HasItem("a"))
+
+		other, err := cache.NewFileWithOptions(c.Dir, cache.FileOptions{})
+		assert.NoError(t, err)
+		assert.False(t, other.HasItem("a"))
+
+		assert.NoError(t, c.Commit())
+		assert.True(t, other.HasItem("a"))
+		assert.Equal(t, "data a", other.GetItem("a").Get())
+
+		item, _ = cache.NewFileItem("b").Set("data b", standards.KeepTTL)
+		assert.NoError(t, c.SaveDeferred(item))
+		assert.NoError(t, c.DeleteItem("b"))
+		assert.NoError(t, c.SaveDeferred(item))
+		assert.NoError(t, c.Discard())
+		assert.NoError(t, c.Commit())
+		assert.False(t, c.HasItem("b"))
+
+		assert.Error(t, c.SaveDeferred(cache.NewMemoryItem("c")))
+	})
+
+	t.Run("Redis", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+		r := cache.NewRedisWithOptions(db, cache.RedisOptions{})
+
+		item, _ := cache.NewRedisItem("a").Set("data a", standards.KeepTTL)
+		item.(cache.TaggedItem).Tag("tag")
+		assert.NoError(t, r.SaveDeferred(item))
+		assert.True(t, r.HasItem("a"))
+		assert.Equal(t, "data a", r.GetItem("a").Get())
+
+		mock.ExpectTxPipeline()
+		mock.ExpectSet("a", "data a", 0).SetVal("OK")
+		mock.ExpectEval(cache.REDIS_TAG_SCRIPT, []string{cache.REDIS_TAG_PREFIX + "tag"}, "a", int64(0)).SetVal(int64(0))
+		mock.ExpectTxPipelineExec()
+		assert.NoError(t, r.Commit())
+
+		mock.ExpectGet("a").SetVal("data a")
+		assert.True(t, r.HasItem("a"))
+
+		item, _ = cache.NewRedisItem("b").Set("data b", standards.KeepTTL)
+		assert.NoError(t, r.SaveDeferred(item))
+		mock.ExpectTxPipeline()
+		mock.ExpectSet("b", "data b", 0).SetErr(errors.New("set error"))
+		assert.Error(t, r.Commit())
+		assert.True(t, r.HasItem("b"))
+
+		assert.NoError(t, r.Discard())
+		assert.NoError(t, r.Commit())
+		mock.ExpectGet("b").RedisNil()
+		assert.False(t, r.HasItem("b"))
+
+		assert.Error(t, r.SaveDeferred(cache.NewMemoryItem("c")))
+
+		// pending items are visible when MGET fails
+		item, _ = cache.NewRedisItem("d").Set("data d", standards.KeepTTL)
+		assert.NoError(t, r.SaveDeferred(item))
+		mock.ExpectMGet("d", "e").SetErr(errors.New("mget error"))
+		items := r.GetItems("d", "e")
+		assert.Len(t, items, 1)
+		assert.Equal(t, "data d", items[0].Get())
+		assert.NoError(t, r.Discard())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"github.com/gouef/cache"
+	"github.com/gouef/standards"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRegisterDriver(t *testing.T) {
+	var opened []string
+	cache.RegisterDriver("test-prefixed", func(dsn *cache.DSN) (standards.Cache, error) {
+		inner, err := dsn.Lookup(dsn.Host)
+		if err != nil {
+			return nil, err
+		}
+		opened = append(opened, dsn.Host)
+		return cache.NewPrefixCache(inner, dsn.Get("prefix")), nil
+	})
+
+	assert.Contains(t, cache.Drivers(), "test-prefixed")
+	assert.Subset(t, cache.Drivers(), []string{"chain", "file", "memory", "redis", "rediss"})
+
+	assert.Panics(t, func() {
+		cache.RegisterDriver("memory", func(dsn *cache.DSN) (standards.Cache, error) { return nil, nil })
+	})
+	assert.Panics(t, func() { cache.RegisterDriver("test-nil", nil) })
+
+	s := cache.NewStorage()
+	err := s.AddConfig(cache.Config{Caches: map[string]string{
+		"users":  "test-prefixed://shared?prefix=users:",
+		"shared": "memory://",
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"shared"}, opened)
+
+	users, _ := s.Get("users")
+	item := users.(cache.ItemFactory).NewItem("a")
+	item.Set("data", standards.KeepTTL)
+	assert.NoError(t, users.Save(item))
+	shared, _ := s.Get("shared")
+	assert.True(t, shared.HasItem("users:a"))
+
+	_, err = s.AddDSN("other", "test-prefixed://shared?prefix=other:&unknown=1")
+	assert.ErrorIs(t, err, cache.ErrInvalidConfig)
+	assert.ErrorContains(t, err, "unknown options unknown")
+
+	_, err = cache.Open("test-prefixed://shared")
+	assert.ErrorIs(t, err, cache.ErrInvalidConfig)
+
+	_, err = s.AddDSN("missing", "test-prefixed://nothing")
+	assert.ErrorIs(t, err, cache.ErrUnknownCache)
+}
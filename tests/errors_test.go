@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"errors"
+	"github.com/go-redis/redismock/v9"
+	"github.com/gouef/cache"
+	"github.com/gouef/standards"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMemory_GetItemE(t *testing.T) {
+	c := cache.NewMemory()
+
+	_, err := c.GetItemE("a")
+	assert.ErrorIs(t, err, cache.ErrMiss)
+	has, err := c.HasItemE("a")
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	item := c.NewItem("a")
+	item.Set("data", time.Hour)
+	assert.NoError(t, c.Save(item))
+	expired := c.NewItem("expired")
+	expired.Set("data", time.Nanosecond)
+	assert.NoError(t, c.Save(expired))
+	time.Sleep(time.Millisecond)
+
+	found, err := c.GetItemE("a")
+	assert.NoError(t, err)
+	assert.Equal(t, "data", found.Get())
+	has, err = c.HasItemE("a")
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	_, err = c.GetItemE("expired")
+	assert.ErrorIs(t, err, cache.ErrMiss)
+}
+
+func TestFile_GetItemE(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "cache_errors_test")
+	_ = os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+
+	c, err := cache.NewFileWithOptions(dir, cache.FileOptions{})
+	assert.NoError(t, err)
+
+	_, err = c.GetItemE("missing")
+	assert.ErrorIs(t, err, cache.ErrMiss)
+
+	item, _ := cache.NewFileItem("a").Set("data", standards.KeepTTL)
+	assert.NoError(t, c.Save(item))
+	found, err := c.GetItemE("a")
+	assert.NoError(t, err)
+	assert.Equal(t, "data", found.Get())
+
+	corrupt := c.FilePath("corrupt")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(corrupt), 0755))
+	assert.NoError(t, os.WriteFile(corrupt, []byte("{not json"), 0644))
+	_, err = c.GetItemE("corrupt")
+	assert.ErrorIs(t, err, cache.ErrCorruptEntry)
+	assert.NoFileExists(t, corrupt)
+
+	// directory in place of cache file can't be read
+	unreadable := c.FilePath("unreadable")
+	assert.NoError(t, os.MkdirAll(unreadable, 0755))
+	_, err = c.GetItemE("unreadable")
+	assert.ErrorIs(t, err, cache.ErrBackendUnavailable)
+	assert.Nil(t, c.GetItem("unreadable"))
+	has, err := c.HasItemE("unreadable")
+	assert.ErrorIs(t, err, cache.ErrBackendUnavailable)
+	assert.False(t, has)
+
+	has, err = c.HasItemE("missing")
+	assert.NoError(t, err)
+	assert.False(t, has)
+}
+
+func TestFileSimple_GetE(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "cache_errors_simple_test")
+	_ = os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+
+	c, err := cache.NewFileSimple(dir)
+	assert.NoError(t, err)
+
+	_, err = c.GetE("missing")
+	assert.ErrorIs(t, err, cache.ErrMiss)
+	has, err := c.HasE("missing")
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	assert.NoError(t, c.Set("a", "data", time.Hour))
+	value, err := c.GetE("a")
+	assert.NoError(t, err)
+	assert.Equal(t, "data", value)
+	has, err = c.HasE("a")
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	corrupt := c.FilePath("corrupt")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(corrupt), 0755))
+	assert.NoError(t, os.WriteFile(corrupt, []byte("{not json"), 0644))
+	_, err = c.GetE("corrupt")
+	assert.ErrorIs(t, err, cache.ErrCorruptEntry)
+
+	assert.NoError(t, os.MkdirAll(c.FilePath("unreadable"), 0755))
+	_, err = c.GetE("unreadable")
+	assert.ErrorIs(t, err, cache.ErrBackendUnavailable)
+	assert.Equal(t, "default", c.Get("unreadable", "default"))
+}
+
+func TestRedis_GetItemE(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	c := cache.NewRedisWithOptions(db, cache.RedisOptions{Codec: cache.JSONCodec{}})
+
+	mock.ExpectGet("missing").RedisNil()
+	_, err := c.GetItemE("missing")
+	assert.ErrorIs(t, err, cache.ErrMiss)
+
+	down := errors.New("connection refused")
+	mock.ExpectGet("a").SetErr(down)
+	_, err = c.GetItemE("a")
+	assert.ErrorIs(t, err, cache.ErrBackendUnavailable)
+	assert.ErrorIs(t, err, down)
+
+	mock.ExpectGet("a").SetErr(down)
+	assert.Nil(t, c.GetItem("a"))
+
+	mock.ExpectGet("corrupt").SetVal("{not json")
+	_, err = c.GetItemE("corrupt")
+	assert.ErrorIs(t, err, cache.ErrCorruptEntry)
+
+	mock.ExpectGet("b").SetVal(`"data"`)
+	item, err := c.GetItemE("b")
+	assert.NoError(t, err)
+	assert.Equal(t, "data", item.Get())
+
+	mock.ExpectGet("a").SetErr(down)
+	has, err := c.HasItemE("a")
+	assert.ErrorIs(t, err, cache.ErrBackendUnavailable)
+	assert.False(t, has)
+
+	mock.ExpectGet("missing").RedisNil()
+	has, err = c.HasItemE("missing")
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	var _ cache.CheckedReader = c
+	var _ cache.CheckedReader = cache.NewMemory()
+}
@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"github.com/gouef/cache"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEvictionPolicy(t *testing.T) {
+	t.Run("LRU", func(t *testing.T) {
+		p := cache.NewLRUPolicy()
+		_, ok := p.Victim()
+		assert.False(t, ok)
+
+		p.Add("a")
+		p.Add("b")
+		p.Add("c")
+		p.Access("a")
+		p.Add("b")
+
+		victim, ok := p.Victim()
+		assert.True(t, ok)
+		assert.Equal(t, "c", victim)
+
+		p.Remove("c")
+		victim, _ = p.Victim()
+		assert.Equal(t, "a", victim)
+	})
+
+	t.Run("FIFO", func(t *testing.T) {
+		p := cache.NewFIFOPolicy()
+		_, ok := p.Victim()
+		assert.False(t, ok)
+
+		p.Add("a")
+		p.Add("b")
+		p.Access("a")
+		p.Add("a")
+
+		victim, ok := p.Victim()
+		assert.True(t, ok)
+		assert.Equal(t, "a", victim)
+
+		p.Remove("a")
+		victim, _ = p.Victim()
+		assert.Equal(t, "b", victim)
+	})
+
+	t.Run("LFU", func(t *testing.T) {
+		p := cache.NewLFUPolicy()
+		_, ok := p.Victim()
+		assert.False(t, ok)
+
+		p.Add("a")
+		p.Add("b")
+		p.Add("c")
+		p.Access("a")
+		p.Access("b")
+		p.Access("b")
+
+		victim, ok := p.Victim()
+		assert.True(t, ok)
+		assert.Equal(t, "c", victim)
+
+		p.Remove("c")
+		victim, _ = p.Victim()
+		assert.Equal(t, "a", victim)
+
+		p.Remove("a")
+		p.Access("missing")
+		victim, _ = p.Victim()
+		assert.Equal(t, "b", victim)
+
+		p.Add("d")
+		victim, _ = p.Victim()
+		assert.Equal(t, "d", victim)
+	})
+
+	t.Run("Random", func(t *testing.T) {
+		p := cache.NewRandomPolicy()
+		_, ok := p.Victim()
+		assert.False(t, ok)
+
+		p.Add("a")
+		p.Add("b")
+		p.Add("a")
+		p.Access("a")
+		p.Remove("a")
+		p.Remove("missing")
+
+		victim, ok := p.Victim()
+		assert.True(t, ok)
+		assert.Equal(t, "b", victim)
+	})
+}
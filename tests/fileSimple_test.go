@@ -107,7 +107,7 @@ func TestFileSimple_SaveAndGetItems(t *testing.T) {
 
 	data := []byte("{{,#)")
 
-	os.WriteFile(filepath.Join(dir, item.GetKey()+".cache"), data, 0644)
+	os.WriteFile(c.FilePath(item.GetKey()), data, 0644)
 
 	invalidItem := c.Get(item.GetKey(), nil)
 
@@ -1,11 +1,14 @@
 package tests
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/gouef/cache"
 	"github.com/gouef/standards"
 	"github.com/stretchr/testify/assert"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -96,7 +99,7 @@ func TestFile_SaveAndGetItems(t *testing.T) {
 
 	data := []byte("{{,#)")
 
-	os.WriteFile(filepath.Join(dir, item.GetKey()+".cache"), data, 0644)
+	os.WriteFile(c.(*cache.File).FilePath(item.GetKey()), data, 0644)
 
 	invalidItem := c.GetItem(item.GetKey())
 
@@ -104,6 +107,45 @@ func TestFile_SaveAndGetItems(t *testing.T) {
 
 }
 
+func TestFile_GetItemsWithConcurrentWrites(t *testing.T) {
+	c, err := cache.NewFile(t.TempDir())
+	assert.NoError(t, err)
+
+	keys := make([]string, 50)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 20; j++ {
+					c.GetItems(keys...)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for _, key := range keys {
+					item, _ := cache.NewFileItem(key).Set("data", time.Minute)
+					_ = c.Save(item)
+				}
+			}()
+		}
+		wg.Wait()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("GetItems deadlocked with concurrent writes")
+	}
+}
+
 func TestFile_InvalidJson(t *testing.T) {
 	dir := setupCacheDir(t)
 	c, err := cache.NewFile(dir)
@@ -312,3 +354,139 @@ func TestFile_Save(t *testing.T) {
 		_ = os.Chmod(filePath, 0644)
 	})
 }
+
+func TestFile_KeyPath(t *testing.T) {
+	t.Run("Unsafe keys stay in directory", func(t *testing.T) {
+		dir := t.TempDir()
+		c, err := cache.NewFileWithOptions(dir, cache.FileOptions{})
+		assert.NoError(t, err)
+
+		for _, key := range []string{"../../etc/x", "a/b", strings.Repeat("k", 300), ""} {
+			item := cache.NewFileItem(key)
+			item.Set("data", standards.KeepTTL)
+			assert.NoError(t, c.Save(item))
+			assert.Equal(t, "data", c.GetItem(key).Get())
+
+			path := c.FilePath(key)
+			rel, err := filepath.Rel(dir, path)
+			assert.NoError(t, err)
+			assert.Len(t, strings.Split(rel, string(filepath.Separator)), cache.FILE_SHARD_DEPTH+1)
+			assert.FileExists(t, path)
+
+			// original key is stored inside file
+			data, err := os.ReadFile(path)
+			assert.NoError(t, err)
+			var stored cache.FileItem
+			assert.NoError(t, json.Unmarshal(data, &stored))
+			assert.Equal(t, key, stored.Key)
+		}
+
+		assert.NoError(t, c.Clear())
+		assert.False(t, c.HasItem("a/b"))
+	})
+
+	t.Run("Shard depth", func(t *testing.T) {
+		dir := t.TempDir()
+		c, err := cache.NewFileWithOptions(dir, cache.FileOptions{ShardDepth: 3})
+		assert.NoError(t, err)
+		rel, _ := filepath.Rel(dir, c.FilePath("key"))
+		assert.Len(t, strings.Split(rel, string(filepath.Separator)), 4)
+
+		c, err = cache.NewFileWithOptions(dir, cache.FileOptions{ShardDepth: -1})
+		assert.NoError(t, err)
+		assert.Equal(t, dir, filepath.Dir(c.FilePath("key")))
+
+		s, err := cache.NewFileSimpleWithOptions(dir, cache.FileOptions{ShardDepth: 1})
+		assert.NoError(t, err)
+		assert.NoError(t, s.Set("../key", "data", standards.KeepTTL))
+		assert.FileExists(t, s.FilePath("../key"))
+		assert.Equal(t, "data", s.Get("../key", nil))
+
+		purged, err := s.Purge()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, purged)
+	})
+}
+
+func TestFile_AtomicWrite(t *testing.T) {
+	t.Run("Concurrent instances never read partial file", func(t *testing.T) {
+		dir := t.TempDir()
+		writer, err := cache.NewFileWithOptions(dir, cache.FileOptions{})
+		assert.NoError(t, err)
+		reader, err := cache.NewFileSimpleWithOptions(dir, cache.FileOptions{})
+		assert.NoError(t, err)
+
+		value := strings.Repeat("x", 1<<16)
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				item := cache.NewFileItem("key")
+				item.Set(value, standards.KeepTTL)
+				assert.NoError(t, writer.Save(item))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				if v := reader.Get("key", nil); v != nil {
+					assert.Equal(t, value, v)
+				}
+			}
+		}()
+		wg.Wait()
+
+		assert.FileExists(t, filepath.Join(dir, cache.FILE_LOCK))
+		assert.Equal(t, value, reader.Get("key", nil))
+	})
+
+	t.Run("Reads don't need lock file", func(t *testing.T) {
+		dir := t.TempDir()
+		c, err := cache.NewFileWithOptions(dir, cache.FileOptions{})
+		assert.NoError(t, err)
+		assert.FileExists(t, filepath.Join(dir, cache.FILE_LOCK))
+
+		item := cache.NewFileItem("key")
+		item.Set("data", standards.KeepTTL)
+		assert.NoError(t, c.Save(item))
+		assert.NoError(t, c.Close())
+
+		// lock file which can't be opened is skipped by readers
+		assert.NoError(t, os.Remove(filepath.Join(dir, cache.FILE_LOCK)))
+		assert.Equal(t, "data", c.GetItem("key").Get())
+		assert.NoFileExists(t, filepath.Join(dir, cache.FILE_LOCK))
+
+		// read-only directory, root bypasses permissions
+		if os.Geteuid() != 0 {
+			reader, err := cache.NewFileWithOptions(dir, cache.FileOptions{})
+			assert.NoError(t, err)
+			assert.NoError(t, os.Chmod(dir, 0555))
+			defer os.Chmod(dir, 0755)
+			assert.Equal(t, "data", reader.GetItem("key").Get())
+		}
+
+		// writers create lock file
+		assert.NoError(t, c.Save(item))
+		assert.FileExists(t, filepath.Join(dir, cache.FILE_LOCK))
+	})
+
+	t.Run("Leftover temporary files are removed", func(t *testing.T) {
+		dir := t.TempDir()
+		c, err := cache.NewFileWithOptions(dir, cache.FileOptions{})
+		assert.NoError(t, err)
+
+		item := cache.NewFileItem("key")
+		item.Set("data", standards.KeepTTL)
+		assert.NoError(t, c.Save(item))
+
+		temp := filepath.Join(filepath.Dir(c.FilePath("key")), ".interrupted"+cache.FILE_TEMP_EXTENSION)
+		assert.NoError(t, os.WriteFile(temp, []byte("{"), 0644))
+
+		purged, err := c.Purge()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, purged)
+		assert.NoFileExists(t, temp)
+		assert.True(t, c.HasItem("key"))
+	})
+}
@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"github.com/gouef/cache"
+	"github.com/gouef/standards"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type purgerMock struct {
+	calls atomic.Int32
+	err   error
+}
+
+func (p *purgerMock) Purge() (int, error) {
+	p.calls.Add(1)
+	return 2, p.err
+}
+
+func TestJanitor(t *testing.T) {
+	t.Run("Runs until Close", func(t *testing.T) {
+		purger := &purgerMock{err: errors.New("purge error")}
+		var lastErr atomic.Value
+		j := cache.StartJanitor(context.Background(), purger, 10*time.Millisecond, func(purged int, err error) {
+			lastErr.Store(err)
+		})
+
+		assert.Eventually(t, func() bool {
+			return purger.calls.Load() >= 2
+		}, time.Second, 5*time.Millisecond)
+
+		assert.NoError(t, j.Close())
+		assert.NoError(t, j.Close())
+
+		calls := purger.calls.Load()
+		assert.Equal(t, uint64(calls)*2, j.Purged())
+		assert.Equal(t, purger.err, lastErr.Load())
+
+		time.Sleep(30 * time.Millisecond)
+		assert.Equal(t, calls, purger.calls.Load())
+	})
+
+	t.Run("Stops with context", func(t *testing.T) {
+		purger := &purgerMock{}
+		ctx, cancel := context.WithCancel(context.Background())
+		j := cache.StartJanitor(ctx, purger, time.Hour, nil)
+		cancel()
+		assert.NoError(t, j.Close())
+		assert.Equal(t, uint64(0), j.Purged())
+	})
+}
+
+func TestMemory_Purge(t *testing.T) {
+	t.Run("Purge", func(t *testing.T) {
+		memory := cache.NewMemory()
+
+		keep, _ := cache.NewMemoryItem("keep").Set("data", standards.KeepTTL)
+		expired, _ := cache.NewMemoryItem("expired").Set("data", standards.KeepTTL)
+		expired.ExpiresAt(time.Now().Add(-time.Second))
+		assert.NoError(t, memory.Save(keep))
+		assert.NoError(t, memory.Save(expired))
+
+		purged, err := memory.Purge()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, purged)
+		assert.Equal(t, 1, memory.Len())
+		assert.NoError(t, memory.Close())
+	})
+
+	t.Run("CleanupInterval", func(t *testing.T) {
+		var total atomic.Int32
+		memory := cache.NewMemoryWithOptions(cache.MemoryOptions{
+			CleanupInterval: 10 * time.Millisecond,
+			OnPurge: func(purged int, err error) {
+				total.Add(int32(purged))
+			},
+		})
+
+		item, _ := cache.NewMemoryItem("expired").Set("data", standards.KeepTTL)
+		item.ExpiresAfter(20 * time.Millisecond)
+		assert.NoError(t, memory.Save(item))
+
+		assert.Eventually(t, func() bool {
+			return memory.Len() == 0
+		}, time.Second, 5*time.Millisecond)
+		assert.NoError(t, memory.Close())
+		assert.Equal(t, int32(1), total.Load())
+	})
+}
+
+func TestFile_Purge(t *testing.T) {
+	dir := t.TempDir()
+	c, err := cache.NewFile(dir)
+	assert.NoError(t, err)
+	fileCache := c.(*cache.File)
+
+	keep := cache.NewFileItem("keep")
+	keep.Set("data", standards.KeepTTL)
+	expired := cache.NewFileItem("expired")
+	expired.Set("data", standards.KeepTTL)
+	expired.ExpiresAt(time.Now().Add(-time.Second))
+	assert.NoError(t, c.Save(keep))
+	assert.NoError(t, c.Save(expired))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "corrupted.cache"), []byte("{{"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "other.txt"), []byte("{{"), 0644))
+
+	purged, err := fileCache.Purge()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, purged)
+	assert.True(t, c.HasItem("keep"))
+	assert.FileExists(t, filepath.Join(dir, "other.txt"))
+
+	fileCache.Dir = filepath.Join(dir, "missing")
+	_, err = fileCache.Purge()
+	assert.Error(t, err)
+}
+
+func TestFileSimple_Purge(t *testing.T) {
+	dir := t.TempDir()
+	c, err := cache.NewFileSimple(dir)
+	assert.NoError(t, err)
+
+	assert.NoError(t, c.Set("keep", "data", standards.KeepTTL))
+	assert.NoError(t, c.SetMultiply(map[string]any{"expired": "data"}, time.Nanosecond))
+
+	j := cache.StartJanitor(context.Background(), c, 10*time.Millisecond, nil)
+	assert.Eventually(t, func() bool {
+		return j.Purged() == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.NoError(t, j.Close())
+
+	assert.True(t, c.Has("keep"))
+	assert.NoFileExists(t, c.FilePath("expired"))
+}
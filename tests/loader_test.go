@@ -0,0 +1,199 @@
+package tests
+
+import (
+	"errors"
+	"github.com/gouef/cache"
+	"github.com/gouef/standards"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type uncomparableCache struct {
+	standards.Cache
+	tags []string
+}
+
+func TestGetOrLoad(t *testing.T) {
+	t.Run("Concurrent misses are loaded once", func(t *testing.T) {
+		memory := cache.NewMemory()
+		var calls atomic.Int32
+
+		var wg sync.WaitGroup
+		results := make([]any, 20)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				value, err := cache.GetOrLoad(memory, "key", time.Minute, func() (any, error) {
+					calls.Add(1)
+					time.Sleep(50 * time.Millisecond)
+					return "value", nil
+				})
+				assert.NoError(t, err)
+				results[i] = value
+			}(i)
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), calls.Load())
+		for _, result := range results {
+			assert.Equal(t, "value", result)
+		}
+		assert.True(t, memory.HasItem("key"))
+	})
+
+	t.Run("Errors are not cached", func(t *testing.T) {
+		memory := cache.NewMemory()
+		loadErr := errors.New("load error")
+
+		_, err := cache.GetOrLoad(memory, "key", time.Minute, func() (any, error) {
+			return nil, loadErr
+		})
+		assert.ErrorIs(t, err, loadErr)
+		assert.False(t, memory.HasItem("key"))
+
+		value, err := cache.GetOrLoad(memory, "key", time.Minute, func() (any, error) {
+			return "value", nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "value", value)
+	})
+
+	t.Run("File", func(t *testing.T) {
+		file, err := cache.NewFile(t.TempDir())
+		assert.NoError(t, err)
+
+		value, err := cache.GetOrLoad(file, "key", 0, func() (any, error) {
+			return "value", nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "value", value)
+		assert.Equal(t, "value", file.GetItem("key").Get())
+	})
+
+	t.Run("Uncomparable cache", func(t *testing.T) {
+		c := uncomparableCache{Cache: cache.NewMemory()}
+
+		value, err := cache.GetOrLoad(c, "key", time.Minute, func() (any, error) {
+			return "value", nil
+		})
+		assert.Error(t, err)
+		assert.Equal(t, "value", value)
+	})
+}
+
+func TestLoader(t *testing.T) {
+	t.Run("ErrorTTL", func(t *testing.T) {
+		loader := cache.NewLoader(cache.NewMemory())
+		loader.ErrorTTL = 50 * time.Millisecond
+		loadErr := errors.New("load error")
+		calls := 0
+		load := func() (any, error) {
+			calls++
+			if calls == 1 {
+				return nil, loadErr
+			}
+			return "value", nil
+		}
+
+		_, err := loader.GetOrLoad("key", time.Minute, load)
+		assert.ErrorIs(t, err, loadErr)
+		_, err = loader.GetOrLoad("key", time.Minute, load)
+		assert.ErrorIs(t, err, loadErr)
+		assert.Equal(t, 1, calls)
+
+		time.Sleep(60 * time.Millisecond)
+
+		value, err := loader.GetOrLoad("key", time.Minute, load)
+		assert.NoError(t, err)
+		assert.Equal(t, "value", value)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Forget", func(t *testing.T) {
+		loader := cache.NewLoader(cache.NewMemory())
+		loader.ErrorTTL = time.Minute
+
+		_, err := loader.GetOrLoad("key", time.Minute, func() (any, error) {
+			return nil, errors.New("load error")
+		})
+		assert.Error(t, err)
+
+		loader.Forget("key")
+		value, err := loader.GetOrLoad("key", time.Minute, func() (any, error) {
+			return "value", nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "value", value)
+	})
+
+	t.Run("Cached error doesn't hide stored value", func(t *testing.T) {
+		memory := cache.NewMemory()
+		loader := cache.NewLoader(memory)
+		loader.ErrorTTL = time.Minute
+		loadErr := errors.New("load error")
+
+		_, err := loader.GetOrLoad("key", time.Minute, func() (any, error) {
+			return nil, loadErr
+		})
+		assert.ErrorIs(t, err, loadErr)
+
+		// value stored by other writer is returned
+		item := memory.NewItem("key")
+		_, _ = item.Set("stored", time.Minute)
+		assert.NoError(t, memory.Save(item))
+		value, err := loader.GetOrLoad("key", time.Minute, func() (any, error) {
+			return nil, loadErr
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "stored", value)
+	})
+}
+
+func TestGetOrLoad_Panic(t *testing.T) {
+	c := cache.NewMemory()
+	started, release := make(chan struct{}), make(chan struct{})
+
+	var panicked any
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() { panicked = recover() }()
+		_, _ = cache.GetOrLoad(c, "a", time.Minute, func() (any, error) {
+			close(started)
+			<-release
+			panic("boom")
+		})
+	}()
+
+	<-started
+	var err error
+	var value any
+	waited := make(chan struct{})
+	go func() {
+		defer close(waited)
+		value, err = cache.GetOrLoad(c, "a", time.Minute, func() (any, error) {
+			return "late", nil
+		})
+	}()
+	// let waiter join the running load
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	<-done
+	<-waited
+
+	assert.Equal(t, "boom", panicked)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "panicked: boom")
+	assert.Nil(t, value)
+
+	// failed load isn't cached, next load runs again
+	value, err = cache.GetOrLoad(c, "a", time.Minute, func() (any, error) {
+		return "data", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "data", value)
+}
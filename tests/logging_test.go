@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"github.com/go-redis/redismock/v9"
+	"github.com/gouef/cache"
+	"github.com/gouef/standards"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLogger() (*slog.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	return slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})), &buf
+}
+
+func TestLogging_FileCorrupt(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "cache_logging_test")
+	_ = os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+
+	logger, buf := newTestLogger()
+	c, err := cache.NewFileWithOptions(dir, cache.FileOptions{Log: cache.LogOptions{Logger: logger, Name: "files"}})
+	assert.NoError(t, err)
+
+	path := c.FilePath("broken")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.NoError(t, os.WriteFile(path, []byte("{not json"), 0644))
+
+	assert.Nil(t, c.GetItem("broken"))
+	assert.Nil(t, c.GetItem("missing"))
+	assert.NoFileExists(t, path)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 1)
+	assert.Contains(t, lines[0], "level=WARN")
+	assert.Contains(t, lines[0], `msg="cache entry is corrupt"`)
+	assert.Contains(t, lines[0], "operation=GetItem")
+	assert.Contains(t, lines[0], "key=broken")
+	assert.Contains(t, lines[0], "cache=files")
+}
+
+func TestLogging_RedisError(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	logger, buf := newTestLogger()
+	c := cache.NewRedisWithOptions(db, cache.RedisOptions{Log: cache.LogOptions{
+		Logger:     logger,
+		ErrorLevel: slog.LevelWarn,
+	}})
+
+	mock.ExpectDel("a").SetErr(errors.New("connection refused"))
+	assert.Error(t, c.DeleteItem("a"))
+
+	mock.ExpectDel("b").SetVal(1)
+	assert.NoError(t, c.DeleteItem("b"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 1)
+	assert.Contains(t, lines[0], "level=WARN")
+	assert.Contains(t, lines[0], `msg="cache operation failed"`)
+	assert.Contains(t, lines[0], "operation=DeleteItem")
+	assert.Contains(t, lines[0], "key=a")
+	assert.Contains(t, lines[0], `error="connection refused"`)
+}
+
+func TestLogging_Slow(t *testing.T) {
+	logger, buf := newTestLogger()
+	c := cache.NewMemoryWithOptions(cache.MemoryOptions{Log: cache.LogOptions{
+		Logger:        logger,
+		SlowThreshold: time.Nanosecond,
+		SlowLevel:     slog.LevelInfo,
+	}})
+
+	item := c.NewItem("a")
+	item.Set("data", standards.KeepTTL)
+	assert.NoError(t, c.Save(item))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 1)
+	assert.Contains(t, lines[0], "level=INFO")
+	assert.Contains(t, lines[0], `msg="cache operation is slow"`)
+	assert.Contains(t, lines[0], "operation=Save")
+	assert.Contains(t, lines[0], "duration=")
+
+	buf.Reset()
+	c.SetLogOptions(cache.LogOptions{Logger: logger})
+	c.GetItem("a")
+	assert.Empty(t, buf.String())
+}
+
+func TestLogging_Storage(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "cache_logging_storage_test")
+	_ = os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+
+	logger, buf := newTestLogger()
+	s := cache.NewStorage()
+	_, err := s.AddMemory("before", cache.PrefixMiddleware("app:"))
+	assert.NoError(t, err)
+	s.SetLogOptions(cache.LogOptions{Logger: logger, SlowThreshold: time.Nanosecond})
+	files, err := s.AddFile("after", dir)
+	assert.NoError(t, err)
+
+	before, _ := s.Get("before")
+	before.GetItem("a")
+	files.GetItem("b")
+
+	out := buf.String()
+	assert.Contains(t, out, "cache=before")
+	assert.Contains(t, out, "key=app:a")
+	assert.Contains(t, out, "cache=after")
+	assert.Contains(t, out, "key=b")
+}
+
+func TestLoggingMiddlewareWithOptions(t *testing.T) {
+	logger, buf := newTestLogger()
+	c := cache.Wrap(cache.NewMemory(), cache.LoggingMiddlewareWithOptions(cache.LogOptions{
+		Logger:        logger,
+		Name:          "users",
+		SlowThreshold: time.Nanosecond,
+	}))
+
+	c.GetItem("a")
+
+	assert.Contains(t, buf.String(), "level=WARN")
+	assert.Contains(t, buf.String(), `msg="cache GetItem"`)
+	assert.Contains(t, buf.String(), "cache=users")
+}
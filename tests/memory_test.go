@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"fmt"
 	"github.com/gouef/cache"
 	"github.com/gouef/standards"
 	"github.com/stretchr/testify/assert"
@@ -109,4 +110,117 @@ func TestMemoryItem(t *testing.T) {
 		assert.False(t, c.IsHit())
 		assert.Nil(t, c.Get())
 	})
+
+	t.Run("Set marks MemoryItem as hit until ttl expires", func(t *testing.T) {
+		item := cache.NewMemoryItem("test")
+		assert.False(t, item.IsHit())
+
+		_, err := item.Set("data", time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, item.IsHit())
+		assert.Equal(t, "data", item.Get())
+
+		_, err = item.Set("data", 50*time.Millisecond)
+		assert.NoError(t, err)
+		assert.True(t, item.IsHit())
+		time.Sleep(60 * time.Millisecond)
+		assert.False(t, item.IsHit())
+		assert.Nil(t, item.Get())
+
+		memory := cache.NewMemory()
+		_, _ = item.Set("data", time.Minute)
+		assert.NoError(t, memory.Save(item))
+		saved := memory.GetItem("test")
+		assert.True(t, saved.IsHit())
+		assert.Equal(t, "data", saved.Get())
+	})
+}
+
+func TestMemoryWithOptions(t *testing.T) {
+	save := func(t *testing.T, memory *cache.Memory, key string, value any) {
+		item, err := cache.NewMemoryItem(key).Set(value, standards.KeepTTL)
+		assert.NoError(t, err)
+		assert.NoError(t, memory.Save(item))
+	}
+
+	t.Run("MaxEntries with default LRU", func(t *testing.T) {
+		var evicted []string
+		memory := cache.NewMemoryWithOptions(cache.MemoryOptions{
+			MaxEntries: 2,
+			OnEvict: func(key string, value any) {
+				evicted = append(evicted, key)
+			},
+		})
+
+		save(t, memory, "a", 1)
+		save(t, memory, "b", 2)
+		assert.NotNil(t, memory.GetItem("a"))
+		save(t, memory, "c", 3)
+
+		assert.Equal(t, 2, memory.Len())
+		assert.Equal(t, []string{"b"}, evicted)
+		assert.True(t, memory.HasItem("a"))
+		assert.False(t, memory.HasItem("b"))
+		assert.True(t, memory.HasItem("c"))
+	})
+
+	t.Run("MaxBytes", func(t *testing.T) {
+		var evicted []string
+		memory := cache.NewMemoryWithOptions(cache.MemoryOptions{
+			MaxBytes: 10,
+			Policy:   cache.NewFIFOPolicy(),
+			OnEvict: func(key string, value any) {
+				evicted = append(evicted, key)
+			},
+		})
+
+		save(t, memory, "a", "12345")
+		save(t, memory, "b", "12345")
+		assert.Equal(t, int64(10), memory.Size())
+
+		save(t, memory, "c", "123")
+		assert.Equal(t, []string{"a"}, evicted)
+		assert.Equal(t, int64(8), memory.Size())
+
+		save(t, memory, "b", "1")
+		assert.Equal(t, int64(4), memory.Size())
+
+		assert.NoError(t, memory.DeleteItem("c"))
+		assert.Equal(t, int64(1), memory.Size())
+
+		assert.NoError(t, memory.Clear())
+		assert.Equal(t, int64(0), memory.Size())
+		assert.Equal(t, 0, memory.Len())
+	})
+
+	t.Run("LFU", func(t *testing.T) {
+		memory := cache.NewMemoryWithOptions(cache.MemoryOptions{
+			MaxEntries: 2,
+			Policy:     cache.NewLFUPolicy(),
+		})
+
+		save(t, memory, "a", 1)
+		save(t, memory, "b", 2)
+		memory.GetItem("a")
+		memory.GetItem("a")
+		memory.GetItem("b")
+		save(t, memory, "c", 3)
+
+		assert.True(t, memory.HasItem("a"))
+		assert.False(t, memory.HasItem("b"))
+		assert.True(t, memory.HasItem("c"))
+	})
+
+	t.Run("Random", func(t *testing.T) {
+		memory := cache.NewMemoryWithOptions(cache.MemoryOptions{
+			MaxEntries: 3,
+			Policy:     cache.NewRandomPolicy(),
+		})
+
+		for i := 0; i < 10; i++ {
+			save(t, memory, fmt.Sprintf("key %d", i), i)
+		}
+
+		assert.Equal(t, 3, memory.Len())
+	})
 }
@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"encoding/json"
+	"expvar"
+	"github.com/gouef/cache"
+	"github.com/gouef/standards"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStorage_StatsByDefault(t *testing.T) {
+	s := cache.NewStorage()
+	c, err := s.AddMemory("memory")
+	assert.NoError(t, err)
+	assert.IsType(t, &cache.StatsCache{}, c)
+
+	c.GetItem("a")
+	assert.Equal(t, uint64(1), s.Stats()["memory"].Misses)
+
+	stats := cache.NewStatsCache(cache.NewMemory())
+	added, err := s.Add("stats", stats)
+	assert.NoError(t, err)
+	assert.Same(t, stats, added)
+
+	prefixed, err := s.AddMemory("prefixed", cache.PrefixMiddleware("app:"))
+	assert.NoError(t, err)
+	assert.IsType(t, &cache.PrefixCache{}, prefixed)
+	prefixed.GetItem("a")
+	assert.Equal(t, uint64(1), s.Stats()["prefixed"].Misses)
+
+	s.EnableStats()
+	same, _ := s.Get("memory")
+	assert.Same(t, c, same)
+	assert.Len(t, s.Stats(), 3)
+
+	recorder := httptest.NewRecorder()
+	cache.NewStorage().MetricsHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	s.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Contains(t, recorder.Body.String(), `cache_misses_total{cache="memory",backend="memory"} 1`+"\n")
+}
+
+func TestStorage_Metrics(t *testing.T) {
+	s := cache.NewStorage()
+	s.EnableStats()
+	c, _ := s.AddMemory(`users "main"`)
+	_, _ = s.Add("plain", cache.NewChain(cache.NewMemory()))
+
+	item := c.(cache.ItemFactory).NewItem("a")
+	item.Set("data", standards.KeepTTL)
+	assert.NoError(t, c.Save(item))
+	c.GetItem("a")
+	c.GetItem("b")
+
+	assert.Equal(t, "memory", cache.BackendName(c))
+	assert.Equal(t, "chain", cache.BackendName(cache.NewChain()))
+
+	t.Run("Prometheus", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		s.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+		assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", recorder.Header().Get("Content-Type"))
+		body := recorder.Body.String()
+		labels := `cache="users \"main\"",backend="memory"`
+		assert.Contains(t, body, "# TYPE cache_hits_total counter\n")
+		assert.Contains(t, body, "cache_hits_total{"+labels+"} 1\n")
+		assert.Contains(t, body, "cache_misses_total{"+labels+"} 1\n")
+		assert.Contains(t, body, "cache_sets_total{"+labels+"} 1\n")
+		assert.Contains(t, body, `cache_hits_total{cache="plain",backend="chain"} 0`+"\n")
+		assert.Contains(t, body, "# TYPE cache_operation_duration_seconds histogram\n")
+		assert.Contains(t, body, "cache_operation_duration_seconds_bucket{"+labels+`,operation="get",le="+Inf"} 2`+"\n")
+		assert.Contains(t, body, "cache_operation_duration_seconds_bucket{"+labels+`,operation="get",le="1"} 2`+"\n")
+		assert.Contains(t, body, "cache_operation_duration_seconds_count{"+labels+`,operation="set"} 1`+"\n")
+		assert.Contains(t, body, "cache_operation_duration_seconds_count{"+labels+`,operation="delete"} 0`+"\n")
+	})
+
+	t.Run("Expvar", func(t *testing.T) {
+		name := "cache_test_" + time.Now().Format("150405.000000000")
+		s.PublishExpvar(name)
+
+		var published map[string]map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(expvar.Get(name).String()), &published))
+		assert.Equal(t, "memory", published[`users "main"`]["backend"])
+		assert.Equal(t, float64(1), published[`users "main"`]["hits"])
+		assert.Equal(t, float64(0.5), published[`users "main"`]["hit_ratio"])
+	})
+}
@@ -0,0 +1,369 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/go-redis/redismock/v9"
+	"github.com/gouef/cache"
+	"github.com/gouef/standards"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrap(t *testing.T) {
+	var calls []string
+	named := func(name string) cache.Middleware {
+		return func(c standards.Cache) standards.Cache {
+			return cache.NewObserveCache(c, func(op cache.Operation) {
+				calls = append(calls, name+":"+op.Name)
+			})
+		}
+	}
+
+	memory := cache.NewMemory()
+	c := cache.Wrap(memory, named("outer"), nil, named("inner"))
+	c.GetItem("key")
+	assert.Equal(t, []string{"inner:GetItem", "outer:GetItem"}, calls)
+
+	assert.Same(t, memory, cache.UnwrapCache(c))
+	assert.Same(t, memory, cache.UnwrapCache(memory))
+	assert.Same(t, memory, cache.Wrap(memory))
+	assert.Equal(t, "memory", cache.BackendName(c))
+}
+
+func TestObserveMiddleware(t *testing.T) {
+	var ops []cache.Operation
+	c := cache.Wrap(cache.NewMemory(), cache.ObserveMiddleware(func(op cache.Operation) {
+		ops = append(ops, op)
+	}))
+
+	item := c.(cache.ItemFactory).NewItem("a")
+	item.Set("data", standards.KeepTTL)
+	assert.NoError(t, c.Save(item))
+	assert.NoError(t, c.SaveDeferred(item))
+	assert.NoError(t, c.Commit())
+	assert.NotNil(t, c.GetItem("a"))
+	assert.Len(t, c.GetItems("a", "b"), 1)
+	assert.False(t, c.HasItem("b"))
+	assert.NoError(t, c.DeleteItem("a"))
+	assert.NoError(t, c.DeleteItems("a", "b"))
+	assert.NoError(t, c.(cache.Discarder).Discard())
+	assert.NoError(t, c.(cache.TagInvalidator).InvalidateTags("tag"))
+	_, err := c.(cache.Purger).Purge()
+	assert.NoError(t, err)
+	assert.NoError(t, c.Clear())
+	assert.Error(t, c.Save(cache.NewFileItem("x")))
+
+	var names []string
+	for _, op := range ops {
+		names = append(names, op.Name)
+	}
+	assert.Equal(t, []string{"Save", "SaveDeferred", "Commit", "GetItem", "GetItems", "HasItem", "DeleteItem",
+		"DeleteItems", "Discard", "InvalidateTags", "Purge", "Clear", "Save"}, names)
+	assert.Equal(t, []string{"a", "b"}, ops[4].Keys)
+	assert.Equal(t, 1, ops[4].Hits)
+	assert.Equal(t, 0, ops[5].Hits)
+	assert.Error(t, ops[12].Err)
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c := cache.Wrap(cache.NewMemory(), cache.LoggingMiddleware(logger))
+
+	c.GetItem("a")
+	assert.Error(t, c.Save(cache.NewFileItem("b")))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], "level=DEBUG")
+	assert.Contains(t, lines[0], `msg="cache GetItem"`)
+	assert.Contains(t, lines[0], "keys=[a]")
+	assert.Contains(t, lines[1], "level=ERROR")
+	assert.Contains(t, lines[1], `error="invalid cache item type"`)
+}
+
+func TestPrefixMiddleware(t *testing.T) {
+	memory := cache.NewMemory()
+	c := cache.Wrap(memory, cache.PrefixMiddleware("app:"))
+
+	item := c.(cache.ItemFactory).NewItem("a")
+	assert.Equal(t, "a", item.GetKey())
+	item, _ = item.Set("data a", standards.KeepTTL)
+	item.(cache.TaggedItem).Tag("tag")
+	assert.Equal(t, "a", item.GetKey())
+	assert.Equal(t, []string{"tag"}, item.(cache.TaggedItem).GetTags())
+	assert.NoError(t, c.Save(item))
+	assert.True(t, memory.HasItem("app:a"))
+	assert.False(t, memory.HasItem("a"))
+
+	plain, _ := cache.NewMemoryItem("b").Set("data b", time.Minute)
+	assert.NoError(t, c.SaveDeferred(plain))
+	assert.NoError(t, c.Commit())
+	assert.True(t, memory.HasItem("app:b"))
+	_, ok := memory.GetItem("app:b").(cache.ExpiringItem).GetExpiration()
+	assert.True(t, ok)
+
+	found := c.GetItem("a")
+	assert.Equal(t, "a", found.GetKey())
+	assert.Equal(t, "data a", found.Get())
+	assert.Nil(t, c.GetItem("x"))
+	assert.True(t, c.HasItem("b"))
+
+	items := c.GetItems("a", "b", "x")
+	assert.Len(t, items, 2)
+	assert.Equal(t, "a", items[0].GetKey())
+	assert.Equal(t, "b", items[1].GetKey())
+
+	expiration, ok := found.(cache.ExpiringItem).GetExpiration()
+	assert.False(t, ok)
+	assert.True(t, expiration.IsZero())
+	found.ExpiresAt(time.Now().Add(time.Hour))
+	_, ok = found.(cache.ExpiringItem).GetExpiration()
+	assert.True(t, ok)
+
+	expired, _ := cache.NewMemoryItem("b").Set("data", time.Minute)
+	expired.ExpiresAt(time.Now().Add(-time.Minute))
+	assert.NoError(t, c.Save(expired))
+	assert.False(t, memory.HasItem("app:b"))
+
+	assert.NoError(t, c.(cache.TagInvalidator).InvalidateTags("tag"))
+	assert.False(t, c.HasItem("a"))
+
+	assert.NoError(t, c.Save(item))
+	assert.NoError(t, c.DeleteItem("a"))
+	assert.False(t, memory.HasItem("app:a"))
+	assert.NoError(t, c.Save(item))
+	assert.NoError(t, c.DeleteItems("a"))
+	assert.False(t, memory.HasItem("app:a"))
+
+	assert.NoError(t, c.SaveDeferred(item))
+	assert.NoError(t, c.(cache.Discarder).Discard())
+	_, err := c.(cache.Purger).Purge()
+	assert.NoError(t, err)
+	assert.NoError(t, c.Clear())
+	assert.Equal(t, 0, memory.Len())
+}
+
+// plainCache hides all methods of cache except standards.Cache
+type plainCache struct {
+	standards.Cache
+}
+
+func TestPrefixMiddleware_Clear(t *testing.T) {
+	file, err := cache.NewFileWithOptions(t.TempDir(), cache.FileOptions{})
+	assert.NoError(t, err)
+
+	for name, backend := range map[string]standards.Cache{
+		"memory": cache.NewMemory(),
+		"file":   file,
+		"chain":  cache.NewChain(cache.NewMemory(), cache.NewMemory()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			users := cache.Wrap(backend, cache.PrefixMiddleware("users:"))
+			sessions := cache.Wrap(backend, cache.StatsMiddleware(), cache.PrefixMiddleware("sessions:"))
+			for _, c := range []standards.Cache{users, sessions} {
+				item, _ := c.(cache.ItemFactory).NewItem("a").Set("data", standards.KeepTTL)
+				assert.NoError(t, c.Save(item))
+			}
+			deferred, _ := users.(cache.ItemFactory).NewItem("b").Set("data", standards.KeepTTL)
+			assert.NoError(t, users.SaveDeferred(deferred))
+
+			assert.NoError(t, users.Clear())
+			assert.False(t, users.HasItem("a"))
+			assert.False(t, users.HasItem("b"))
+			assert.True(t, sessions.HasItem("a"))
+
+			assert.NoError(t, sessions.(cache.ContextCache).ClearContext(context.Background()))
+			assert.False(t, sessions.HasItem("a"))
+		})
+	}
+
+	t.Run("redis", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+		c := cache.Wrap(cache.NewRedisWithOptions(db, cache.RedisOptions{Prefix: "app:"}), cache.PrefixMiddleware("users:"))
+		mock.ExpectScan(0, "app:users:*", cache.REDIS_SCAN_COUNT).SetVal([]string{"app:users:a"}, 0)
+		mock.ExpectUnlink("app:users:a").SetVal(1)
+		mock.ExpectScan(0, "app:__compute:users:*", cache.REDIS_SCAN_COUNT).SetVal(nil, 0)
+		mock.ExpectScan(0, "app:__idle:users:*", cache.REDIS_SCAN_COUNT).SetVal(nil, 0)
+		assert.NoError(t, c.Clear())
+
+		mock.ExpectScan(0, "app:users:*", cache.REDIS_SCAN_COUNT).SetErr(errors.New("connection refused"))
+		assert.Error(t, c.Clear())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not supported", func(t *testing.T) {
+		memory := cache.NewMemory()
+		other, _ := memory.NewItem("a").Set("data", standards.KeepTTL)
+		assert.NoError(t, memory.Save(other))
+
+		c := cache.NewPrefixCache(plainCache{Cache: memory}, "users:")
+		assert.ErrorIs(t, c.Clear(), cache.ErrNotSupported)
+		assert.True(t, memory.HasItem("a"))
+		assert.ErrorIs(t, cache.NewChain(memory, plainCache{Cache: cache.NewMemory()}).ClearPrefix("users:"), cache.ErrNotSupported)
+	})
+}
+
+func TestValidateMiddleware(t *testing.T) {
+	memory := cache.NewMemory()
+	c := cache.Wrap(memory, cache.ValidateMiddleware(nil))
+
+	assert.NoError(t, cache.ValidateKey("user:1"))
+	for _, key := range []string{"", "a\nb", string([]byte{0xff}), strings.Repeat("k", cache.MAX_KEY_LENGTH+1)} {
+		assert.ErrorIs(t, cache.ValidateKey(key), cache.ErrInvalidKey)
+
+		item := c.(cache.ItemFactory).NewItem(key)
+		item.Set("data", standards.KeepTTL)
+		assert.ErrorIs(t, c.Save(item), cache.ErrInvalidKey)
+		assert.ErrorIs(t, c.SaveDeferred(item), cache.ErrInvalidKey)
+		assert.ErrorIs(t, c.DeleteItem(key), cache.ErrInvalidKey)
+		assert.ErrorIs(t, c.DeleteItems("ok", key), cache.ErrInvalidKey)
+		assert.Nil(t, c.GetItem(key))
+		assert.False(t, c.HasItem(key))
+	}
+	assert.Equal(t, 0, memory.Len())
+
+	item := c.(cache.ItemFactory).NewItem("ok")
+	item.Set("data", standards.KeepTTL)
+	assert.NoError(t, c.Save(item))
+	assert.NoError(t, c.SaveDeferred(item))
+	assert.NoError(t, c.Commit())
+	assert.True(t, c.HasItem("ok"))
+	assert.NotNil(t, c.GetItem("ok"))
+	assert.Len(t, c.GetItems("ok", ""), 1)
+	assert.Empty(t, c.GetItems(""))
+	assert.NoError(t, c.DeleteItems("ok"))
+	assert.NoError(t, c.DeleteItem("ok"))
+	assert.NoError(t, c.(cache.Discarder).Discard())
+	assert.NoError(t, c.(cache.TagInvalidator).InvalidateTags("tag"))
+	_, err := c.(cache.Purger).Purge()
+	assert.NoError(t, err)
+	assert.NoError(t, c.Clear())
+
+	custom := cache.Wrap(memory, cache.ValidateMiddleware(func(key string) error {
+		if strings.HasPrefix(key, "_") {
+			return errors.New("reserved key")
+		}
+		return nil
+	}))
+	assert.EqualError(t, custom.DeleteItem("_a"), "reserved key")
+}
+
+func TestStorage_Use(t *testing.T) {
+	var ops []string
+	s := cache.NewStorage()
+	s.Use(cache.ObserveMiddleware(func(op cache.Operation) {
+		ops = append(ops, op.Name)
+	}))
+
+	c, err := s.AddMemory("memory", cache.PrefixMiddleware("app:"))
+	assert.NoError(t, err)
+	assert.IsType(t, &cache.ObserveCache{}, c)
+	assert.IsType(t, &cache.PrefixCache{}, c.(cache.Unwrapper).Unwrap())
+
+	c.GetItem("a")
+	assert.Equal(t, []string{"GetItem"}, ops)
+
+	f, err := s.AddFile("file", t.TempDir(), cache.StatsMiddleware())
+	assert.NoError(t, err)
+	f.GetItem("a")
+	assert.Equal(t, uint64(1), s.Stats()["file"].Misses)
+
+	s.EnableStats()
+	wrapped, _ := s.Get("file")
+	assert.IsType(t, &cache.ObserveCache{}, wrapped)
+
+	_, err = s.AddFileWithOptions("file 2", t.TempDir(), cache.FileOptions{}, cache.StatsMiddleware())
+	assert.NoError(t, err)
+	_, err = s.AddMemoryWithOptions("memory 2", cache.MemoryOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, s.Stats(), 4)
+
+	typed := cache.NewTyped[int](c)
+	assert.NoError(t, typed.Set("n", 1, time.Minute))
+	n, ok, err := typed.Get("n")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 1, n)
+}
+
+func TestMiddleware_Forwarding(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	var ops []cache.Operation
+	stats := cache.StatsMiddleware()
+	c := cache.Wrap(cache.NewRedis(db),
+		cache.ObserveMiddleware(func(op cache.Operation) {
+			ops = append(ops, op)
+		}),
+		stats,
+		cache.ValidateMiddleware(nil),
+		cache.PrefixMiddleware("app:"),
+	)
+
+	t.Run("Context", func(t *testing.T) {
+		ctxCache, ok := cache.Wrap(cache.NewMemory(), cache.ValidateMiddleware(nil), cache.PrefixMiddleware("app:")).(cache.ContextCache)
+		assert.True(t, ok)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.Nil(t, ctxCache.GetItemContext(ctx, "a"))
+		assert.False(t, ctxCache.HasItemContext(ctx, "a"))
+		assert.ErrorIs(t, ctxCache.SaveContext(ctx, cache.NewMemoryItem("a")), context.Canceled)
+		assert.ErrorIs(t, ctxCache.DeleteItemContext(ctx, "a"), context.Canceled)
+		assert.ErrorIs(t, ctxCache.CommitContext(ctx), context.Canceled)
+
+		assert.NoError(t, ctxCache.SaveContext(context.Background(), cache.NewMemoryItem("a")))
+		item := ctxCache.GetItemContext(context.Background(), "a")
+		assert.NotNil(t, item)
+		assert.Equal(t, "a", item.GetKey())
+
+		mock.ExpectGet("app:a").SetVal("data")
+		assert.NotNil(t, c.(cache.ContextCache).GetItemContext(context.Background(), "a"))
+		assert.Equal(t, "GetItem", ops[len(ops)-1].Name)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("CheckedReader", func(t *testing.T) {
+		reader, ok := c.(cache.CheckedReader)
+		assert.True(t, ok)
+
+		mock.ExpectGet("app:a").SetErr(errors.New("connection refused"))
+		_, err := reader.GetItemE("a")
+		assert.ErrorIs(t, err, cache.ErrBackendUnavailable)
+		assert.ErrorIs(t, ops[len(ops)-1].Err, cache.ErrBackendUnavailable)
+
+		mock.ExpectGet("app:b").RedisNil()
+		_, err = reader.GetItemE("b")
+		assert.ErrorIs(t, err, cache.ErrMiss)
+		assert.NoError(t, ops[len(ops)-1].Err)
+
+		mock.ExpectGet("app:c").SetVal("data")
+		item, err := reader.GetItemE("c")
+		assert.NoError(t, err)
+		assert.Equal(t, "c", item.GetKey())
+
+		_, err = reader.HasItemE("")
+		assert.ErrorIs(t, err, cache.ErrInvalidKey)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("SaveMany", func(t *testing.T) {
+		mock.CustomMatch(matchArgs(3)).ExpectSet("app:a", "data", time.Minute).SetVal("OK")
+		assert.NoError(t, cache.NewSimple(c).SetMultiply(map[string]any{"a": "data"}, time.Minute))
+		assert.Equal(t, "SaveMany", ops[len(ops)-1].Name)
+		assert.Equal(t, []string{"a"}, ops[len(ops)-1].Keys)
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		assert.ErrorIs(t, cache.NewSimple(c).SetMultiply(map[string]any{"": "data"}, time.Minute), cache.ErrInvalidKey)
+	})
+
+	provider := c.(cache.Unwrapper).Unwrap().(cache.StatsProvider)
+	assert.Equal(t, uint64(2), provider.Stats().Hits)
+	assert.Equal(t, uint64(3), provider.Stats().Misses)
+	assert.Equal(t, uint64(1), provider.Stats().Sets)
+}
@@ -17,7 +17,7 @@ func TestRedis(t *testing.T) {
 	t.Run("Redis with basic functions", func(t *testing.T) {
 		db, mock := redismock.NewClientMock()
 
-		mock.ExpectGet("test").SetErr(errors.New("not found"))
+		mock.ExpectMGet("test").SetErr(errors.New("not found"))
 
 		r := cache.NewRedis(db)
 		item, err := cache.NewRedisItem("test").Set("data", standards.KeepTTL)
@@ -43,15 +43,13 @@ func TestRedis(t *testing.T) {
 
 		mock.ExpectGet("test").SetVal("data")
 		assert.True(t, r.HasItem("test"))
+		mock.ExpectGet("test").SetVal("data")
 		assert.NotNil(t, r.GetItem("test"))
 
 		mock.ExpectDel("test", "test 3").SetVal(0)
 		assert.NoError(t, r.DeleteItems("test", "test 3"))
 
-		mock.ExpectGet("test").SetVal("")
-		mock.ExpectGet("test 2").SetVal("test data")
-		mock.ExpectGet("test 3").SetVal("")
-		mock.ExpectGet("non-exists").SetVal("")
+		mock.ExpectMGet("test", "test 2", "test 3", "non-exists").SetVal([]interface{}{nil, "test data", nil, nil})
 		assert.Equal(t, 1, len(r.GetItems("test", "test 2", "test 3", "non-exists")))
 
 		mock.ExpectDel("test 2").SetVal(0)
@@ -60,22 +58,15 @@ func TestRedis(t *testing.T) {
 		mock.ExpectGet("test 2").SetVal("")
 		assert.False(t, r.HasItem("test 2"))
 
-		mock.ExpectGet("test").SetVal("")
-		mock.ExpectGet("test 2").SetVal("")
-		mock.ExpectGet("test 3").SetVal("")
-		mock.ExpectGet("non-exists").SetVal("")
+		mock.ExpectMGet("test", "test 2", "test 3", "non-exists").SetVal([]interface{}{nil, nil, nil, ""})
 		assert.Equal(t, 0, len(r.GetItems("test", "test 2", "test 3", "non-exists")))
 
-		mock.ExpectSet("test 3", "test data 3", 0).SetVal("test data 3")
 		assert.NoError(t, r.SaveDeferred(item3))
 
-		mock.ExpectFlushAll().SetVal("")
+		mock.ExpectFlushDB().SetVal("")
 		assert.NoError(t, r.Clear())
 
-		mock.ExpectGet("test").SetVal("")
-		mock.ExpectGet("test 2").SetVal("")
-		mock.ExpectGet("test 3").SetVal("")
-		mock.ExpectGet("non-exists").SetVal("")
+		mock.ExpectMGet("test", "test 2", "test 3", "non-exists").SetVal([]interface{}{nil, nil, nil, ""})
 		assert.Equal(t, 0, len(r.GetItems("test", "test 2", "test 3", "non-exists")))
 
 		assert.Nil(t, r.Commit())
@@ -150,3 +141,102 @@ func TestNewsInfoForCache(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestRedis_Prefix(t *testing.T) {
+	t.Run("Keys are prefixed", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+		r := cache.NewRedisWithOptions(db, cache.RedisOptions{Prefix: "app:"})
+
+		item, _ := cache.NewRedisItem("test").Set("data", standards.KeepTTL)
+		item.(cache.TaggedItem).Tag("tag")
+		mock.ExpectSet("app:test", "data", 0).SetVal("OK")
+		mock.ExpectEval(cache.REDIS_TAG_SCRIPT, []string{"app:" + cache.REDIS_TAG_PREFIX + "tag"}, "app:test", int64(0)).SetVal(int64(0))
+		assert.NoError(t, r.Save(item))
+
+		mock.ExpectGet("app:test").SetVal("data")
+		cached := r.GetItem("test")
+		assert.Equal(t, "test", cached.GetKey())
+		assert.Equal(t, "data", cached.Get())
+
+		mock.ExpectGet("app:test").SetVal("data")
+		assert.True(t, r.HasItem("test"))
+
+		mock.ExpectDel("app:test").SetVal(1)
+		assert.NoError(t, r.DeleteItem("test"))
+
+		mock.ExpectDel("app:a", "app:b").SetVal(2)
+		assert.NoError(t, r.DeleteItems("a", "b"))
+
+		mock.ExpectSUnion("app:" + cache.REDIS_TAG_PREFIX + "tag").SetVal([]string{"app:test"})
+		mock.ExpectDel("app:test", "app:"+cache.REDIS_TAG_PREFIX+"tag").SetVal(2)
+		assert.NoError(t, r.InvalidateTags("tag"))
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Clear without prefix doesn't flush database when disabled", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+		r := cache.NewRedisWithOptions(db, cache.RedisOptions{DisableFlushDB: true})
+		assert.ErrorIs(t, r.Clear(), cache.ErrFlushNotAllowed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Clear removes only prefixed keys", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+		r := cache.NewRedisWithOptions(db, cache.RedisOptions{Prefix: "app*[1]:"})
+
+		match := `app\*\[1\]:*`
+		mock.ExpectScan(0, match, cache.REDIS_SCAN_COUNT).SetVal([]string{"app*[1]:a", "app*[1]:b"}, 7)
+		mock.ExpectUnlink("app*[1]:a", "app*[1]:b").SetVal(2)
+		mock.ExpectScan(7, match, cache.REDIS_SCAN_COUNT).SetVal([]string{}, 0)
+		assert.NoError(t, r.Clear())
+
+		mock.ExpectScan(0, match, cache.REDIS_SCAN_COUNT).SetVal([]string{"app*[1]:a"}, 0)
+		mock.ExpectUnlink("app*[1]:a").SetErr(errors.New("unlink error"))
+		assert.Error(t, r.Clear())
+
+		mock.ExpectScan(0, match, cache.REDIS_SCAN_COUNT).SetErr(errors.New("scan error"))
+		assert.Error(t, r.Clear())
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRedis_Batch(t *testing.T) {
+	t.Run("GetItems uses MGET", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+		r := cache.NewRedisWithOptions(db, cache.RedisOptions{Prefix: "app:", Codec: cache.JSONCodec{}})
+
+		mock.ExpectMGet("app:a", "app:b", "app:c", "app:d").SetVal([]interface{}{`"data a"`, nil, `{`, `2`})
+		items := r.GetItems("a", "b", "c", "d")
+		assert.Len(t, items, 2)
+		assert.Equal(t, "a", items[0].GetKey())
+		assert.Equal(t, "data a", items[0].Get())
+		assert.Equal(t, "d", items[1].GetKey())
+		assert.Equal(t, float64(2), items[1].Get())
+
+		assert.Empty(t, r.GetItems())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("SaveMany uses pipeline", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+		r := cache.NewRedisWithOptions(db, cache.RedisOptions{})
+
+		a, _ := cache.NewRedisItem("a").Set("data a", standards.KeepTTL)
+		b, _ := cache.NewRedisItem("b").Set("data b", standards.KeepTTL)
+		b.(cache.TaggedItem).Tag("tag")
+
+		mock.ExpectSet("a", "data a", 0).SetVal("OK")
+		mock.ExpectSet("b", "data b", 0).SetVal("OK")
+		mock.ExpectEval(cache.REDIS_TAG_SCRIPT, []string{cache.REDIS_TAG_PREFIX + "tag"}, "b", int64(0)).SetVal(int64(0))
+		assert.NoError(t, r.SaveMany(a, b))
+
+		mock.ExpectSet("a", "data a", 0).SetErr(errors.New("set error"))
+		assert.Error(t, r.SaveMany(a))
+
+		assert.NoError(t, r.SaveMany())
+		assert.Error(t, r.SaveMany(a, cache.NewMemoryItem("c")))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"errors"
+	"github.com/go-redis/redismock/v9"
+	"github.com/gouef/cache"
+	"github.com/gouef/standards"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type refreshUser struct {
+	Name    string `json:"name"`
+	Version int64  `json:"version"`
+}
+
+func TestRefresher_StaleWhileRevalidate(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "cache_refresh_test")
+	_ = os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+	fileCache, err := cache.NewFile(dir)
+	assert.NoError(t, err)
+
+	for name, c := range map[string]standards.Cache{"memory": cache.NewMemory(), "file": fileCache} {
+		t.Run(name, func(t *testing.T) {
+			var loads atomic.Int64
+			r := cache.NewRefresher[refreshUser](c, func(key string) (refreshUser, error) {
+				return refreshUser{Name: key, Version: loads.Add(1)}, nil
+			}, cache.RefreshOptions{SoftTTL: 50 * time.Millisecond, HardTTL: time.Hour})
+
+			user, err := r.Get("alice")
+			assert.NoError(t, err)
+			assert.Equal(t, refreshUser{Name: "alice", Version: 1}, user)
+
+			user, err = r.Get("alice")
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), user.Version, "fresh value is not reloaded")
+
+			time.Sleep(60 * time.Millisecond)
+
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					user, err := r.Get("alice")
+					assert.NoError(t, err)
+					// stale value until background refresh finishes
+					assert.Contains(t, []int64{1, 2}, user.Version)
+				}()
+			}
+			wg.Wait()
+			r.Wait()
+			assert.Equal(t, int64(2), loads.Load(), "stale value is refreshed once")
+
+			user, err = r.Get("alice")
+			assert.NoError(t, err)
+			assert.Equal(t, int64(2), user.Version)
+		})
+	}
+}
+
+func TestRefresher_HardTTLAndErrors(t *testing.T) {
+	var fail atomic.Bool
+	var loads atomic.Int64
+	var refreshErrors []string
+	r := cache.NewRefresher[int64](cache.NewMemory(), func(key string) (int64, error) {
+		if fail.Load() {
+			return 0, errors.New("database down")
+		}
+		return loads.Add(1), nil
+	}, cache.RefreshOptions{
+		SoftTTL:        20 * time.Millisecond,
+		HardTTL:        80 * time.Millisecond,
+		OnRefreshError: func(key string, err error) { refreshErrors = append(refreshErrors, key+": "+err.Error()) },
+	})
+
+	value, err := r.Get("a")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), value)
+
+	fail.Store(true)
+	time.Sleep(30 * time.Millisecond)
+	value, err = r.Get("a")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), value, "stale value is kept when refresh fails")
+	r.Wait()
+	assert.Equal(t, []string{"a: database down"}, refreshErrors)
+
+	time.Sleep(60 * time.Millisecond)
+	_, err = r.Get("a")
+	assert.EqualError(t, err, "database down", "value after HardTTL is loaded synchronously")
+
+	fail.Store(false)
+	value, err = r.Refresh("a")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), value)
+}
+
+func TestRefresher_RefreshAhead(t *testing.T) {
+	var loads atomic.Int64
+	r := cache.NewRefresher[int64](cache.NewMemory(), func(key string) (int64, error) {
+		return loads.Add(1), nil
+	}, cache.RefreshOptions{SoftTTL: time.Hour, RefreshAhead: 2 * time.Hour})
+
+	value, err := r.Get("a")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), value)
+
+	value, err = r.Get("a")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), value, "value is returned while it is reloaded ahead")
+	r.Wait()
+	assert.Equal(t, int64(2), loads.Load())
+}
+
+func TestRefresher_Redis(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	r := cache.NewRefresher[string](cache.NewRedis(db), func(key string) (string, error) {
+		return "value of " + key, nil
+	}, cache.RefreshOptions{SoftTTL: time.Minute})
+
+	mock.ExpectGet("a").RedisNil()
+	mock.Regexp().ExpectSet("a", `"value":"value of a"`, 0).SetVal("OK")
+	value, err := r.Get("a")
+	assert.NoError(t, err)
+	assert.Equal(t, "value of a", value)
+
+	mock.ExpectGet("a").SetVal(`{"value":"stored","fresh_until":"2100-01-01T00:00:00Z"}`)
+	value, err = r.Get("a")
+	assert.NoError(t, err)
+	assert.Equal(t, "stored", value)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
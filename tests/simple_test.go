@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"errors"
+	"github.com/go-redis/redismock/v9"
+	"github.com/gouef/cache"
+	"github.com/gouef/standards"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSimple(t *testing.T) {
+	t.Run("Implementations", func(t *testing.T) {
+		fileSimple, err := cache.NewFileSimple(t.TempDir())
+		assert.NoError(t, err)
+		file, err := cache.NewFile(t.TempDir())
+		assert.NoError(t, err)
+
+		for name, c := range map[string]cache.SimpleCache{
+			"FileSimple":   fileSimple,
+			"MemorySimple": cache.NewMemorySimple(),
+			"Simple":       cache.NewSimple(file),
+		} {
+			t.Run(name, func(t *testing.T) {
+				assert.Equal(t, "default", c.Get("key", "default"))
+				assert.False(t, c.Has("key"))
+
+				assert.NoError(t, c.Set("key", "value", standards.KeepTTL))
+				assert.True(t, c.Has("key"))
+				assert.Equal(t, "value", c.Get("key", "default"))
+
+				assert.NoError(t, c.SetMultiply(map[string]any{"a": "value a", "b": "value b"}, time.Minute))
+				assert.Equal(t, []any{"value a", "value b"}, c.GetMultiply([]string{"a", "b", "non-exists"}, nil))
+				assert.Equal(t, []any{"value a", "default"}, c.GetMultiply([]string{"a", "non-exists"}, "default"))
+
+				assert.NoError(t, c.Delete("key"))
+				assert.False(t, c.Has("key"))
+				assert.NoError(t, c.DeleteMultiply("a", "b"))
+				assert.False(t, c.Has("a"))
+
+				assert.NoError(t, c.Set("key", "value", standards.KeepTTL))
+				assert.NoError(t, c.Clear())
+				assert.False(t, c.Has("key"))
+			})
+		}
+	})
+
+	t.Run("AllowDefaultNil", func(t *testing.T) {
+		c := cache.NewMemorySimpleWithDefaultNil(true)
+		assert.True(t, c.Has("non-exists"))
+		assert.Equal(t, []any{nil, nil}, c.GetMultiply([]string{"a", "b"}, nil))
+
+		c = cache.NewMemorySimpleWithDefaultNil(false)
+		assert.False(t, c.Has("non-exists"))
+		assert.Equal(t, []any{}, c.GetMultiply([]string{"a", "b"}, nil))
+	})
+
+	t.Run("MemorySimple", func(t *testing.T) {
+		c := cache.NewMemorySimpleWithOptions(cache.MemoryOptions{MaxEntries: 1})
+		assert.NoError(t, c.Set("a", "value", time.Millisecond))
+		assert.NoError(t, c.Set("b", "value", time.Millisecond))
+		assert.Equal(t, 1, c.Memory().Len())
+
+		time.Sleep(5 * time.Millisecond)
+		assert.False(t, c.Has("b"))
+		purged, err := c.Purge()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, purged)
+		assert.NoError(t, c.Close())
+	})
+
+	t.Run("RedisSimple", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+		c := cache.NewRedisSimpleWithOptions(db, cache.RedisOptions{Prefix: "app:"})
+		assert.NotNil(t, c.Redis())
+
+		mock.ExpectGet("app:key").SetVal("value")
+		assert.Equal(t, "value", c.Get("key", "default"))
+		mock.ExpectGet("app:key").RedisNil()
+		assert.Equal(t, "default", c.Get("key", "default"))
+
+		mock.ExpectMGet("app:a", "app:b").SetVal([]interface{}{"value a", nil})
+		assert.Equal(t, []any{"value a", "default"}, c.GetMultiply([]string{"a", "b"}, "default"))
+
+		mock.ExpectSet("app:a", "value a", 0).SetVal("OK")
+		assert.NoError(t, c.SetMultiply(map[string]any{"a": "value a"}, standards.KeepTTL))
+
+		mock.ExpectSet("app:a", "value a", 0).SetErr(errors.New("set error"))
+		assert.Error(t, c.Set("a", "value a", standards.KeepTTL))
+
+		mock.ExpectDel("app:a", "app:b").SetVal(2)
+		assert.NoError(t, c.DeleteMultiply("a", "b"))
+
+		purged, err := c.Purge()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, purged)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		assert.False(t, cache.NewRedisSimpleWithDefaultNil(db, false).AllowDefaultNil)
+		assert.NotNil(t, cache.NewRedisSimple(db))
+	})
+
+	t.Run("Cache without ItemFactory", func(t *testing.T) {
+		c := cache.NewSimple(&noFactoryCache{Memory: cache.NewMemory()})
+		assert.Error(t, c.Set("key", "value", standards.KeepTTL))
+		assert.Error(t, c.SetMultiply(map[string]any{"key": "value"}, standards.KeepTTL))
+		assert.IsType(t, &noFactoryCache{}, c.Cache())
+	})
+}
+
+// noFactoryCache hides NewItem of Memory, so it doesn't implement ItemFactory
+type noFactoryCache struct {
+	*cache.Memory
+}
+
+func (c *noFactoryCache) NewItem() {}
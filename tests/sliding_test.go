@@ -0,0 +1,238 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-redis/redismock/v9"
+	"github.com/gouef/cache"
+	"github.com/gouef/standards"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// saveSliding saves item of key which expires after idle, but not later than after maxLifetime
+func saveSliding(t *testing.T, c standards.Cache, key string, idle, maxLifetime time.Duration) {
+	item := c.(cache.ItemFactory).NewItem(key)
+	item.Set("data", idle)
+	item.(cache.SlidingItem).ExpiresAfterIdle(idle, maxLifetime)
+	assert.NoError(t, c.Save(item))
+}
+
+func expirationOf(t *testing.T, item standards.CacheItem) time.Time {
+	expiration, ok := item.(cache.ExpiringItem).GetExpiration()
+	assert.True(t, ok)
+	return expiration
+}
+
+func TestSliding(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "cache_sliding_test")
+	_ = os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+	fileCache, err := cache.NewFileWithOptions(dir, cache.FileOptions{Codec: cache.JSONCodec{}})
+	assert.NoError(t, err)
+
+	for name, c := range map[string]standards.Cache{
+		"memory": cache.NewMemory(),
+		"file":   fileCache,
+	} {
+		t.Run(name, func(t *testing.T) {
+			saveSliding(t, c, "session", 200*time.Millisecond, 0)
+
+			// every hit pushes expiration forward
+			for i := 0; i < 3; i++ {
+				time.Sleep(120 * time.Millisecond)
+				item := c.GetItem("session")
+				assert.NotNil(t, item)
+				assert.WithinDuration(t, time.Now().Add(200*time.Millisecond), expirationOf(t, item), 50*time.Millisecond)
+			}
+
+			// idle item expires
+			time.Sleep(250 * time.Millisecond)
+			assert.False(t, c.HasItem("session"))
+
+			// max lifetime caps sliding
+			saveSliding(t, c, "capped", time.Hour, time.Minute)
+			item := c.GetItem("capped")
+			assert.NotNil(t, item)
+			idle, maxExpiration := item.(cache.SlidingItem).GetIdleTimeout()
+			assert.Equal(t, time.Hour, idle)
+			assert.WithinDuration(t, time.Now().Add(time.Minute), maxExpiration, time.Second)
+			assert.Equal(t, maxExpiration, expirationOf(t, item))
+
+			// items without idle timeout don't slide
+			fixed := c.(cache.ItemFactory).NewItem("fixed")
+			fixed.Set("data", time.Minute)
+			fixed.ExpiresAfter(time.Minute)
+			assert.NoError(t, c.Save(fixed))
+			expiration := expirationOf(t, c.GetItem("fixed"))
+			time.Sleep(10 * time.Millisecond)
+			assert.Equal(t, expiration, expirationOf(t, c.GetItem("fixed")))
+
+			// zero idle without max lifetime keeps expiration
+			kept := c.(cache.ItemFactory).NewItem("kept")
+			kept.Set("data", time.Minute)
+			kept.ExpiresAfter(time.Minute)
+			kept.(cache.SlidingItem).ExpiresAfterIdle(0, 0)
+			assert.NoError(t, c.Save(kept))
+			assert.True(t, c.HasItem("kept"))
+			assert.WithinDuration(t, time.Now().Add(time.Minute), expirationOf(t, c.GetItem("kept")), time.Second)
+		})
+	}
+}
+
+func TestSliding_Prefix(t *testing.T) {
+	memory := cache.NewMemory()
+	c := cache.Wrap(memory, cache.PrefixMiddleware("app:"))
+	saveSliding(t, c, "session", 200*time.Millisecond, time.Hour)
+
+	item := c.GetItem("session")
+	assert.NotNil(t, item)
+	idle, maxExpiration := item.(cache.SlidingItem).GetIdleTimeout()
+	assert.Equal(t, 200*time.Millisecond, idle)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), maxExpiration, time.Second)
+
+	// hits through prefix push expiration of stored item forward
+	for i := 0; i < 3; i++ {
+		time.Sleep(120 * time.Millisecond)
+		assert.NotNil(t, c.GetItem("session"))
+	}
+	assert.WithinDuration(t, time.Now().Add(200*time.Millisecond), expirationOf(t, memory.GetItem("app:session")), 50*time.Millisecond)
+}
+
+func TestSliding_FilePersisted(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "cache_sliding_file_test")
+	_ = os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+	c, err := cache.NewFileWithOptions(dir, cache.FileOptions{})
+	assert.NoError(t, err)
+
+	saveSliding(t, c, "session", 200*time.Millisecond, time.Hour)
+	saved := expirationOf(t, c.GetItem("session"))
+	time.Sleep(50 * time.Millisecond)
+	assert.NotNil(t, c.GetItem("session"))
+
+	// other instance reads expiration written by last hit
+	other, err := cache.NewFileWithOptions(dir, cache.FileOptions{})
+	assert.NoError(t, err)
+	item := other.GetItem("session")
+	assert.NotNil(t, item)
+	assert.True(t, expirationOf(t, item).After(saved))
+	idle, maxExpiration := item.(cache.SlidingItem).GetIdleTimeout()
+	assert.Equal(t, 200*time.Millisecond, idle)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), maxExpiration, time.Second)
+
+	// hit which moves expiration by less than idle / FILE_SLIDE_DIVISOR doesn't rewrite file
+	saveSliding(t, c, "long", time.Minute, 0)
+	data, err := os.ReadFile(c.FilePath("long"))
+	assert.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	assert.NotNil(t, c.GetItem("long"))
+	hit, err := os.ReadFile(c.FilePath("long"))
+	assert.NoError(t, err)
+	assert.Equal(t, data, hit)
+
+	// FileSimple slides items of File with the same threshold
+	simple, err := cache.NewFileSimple(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, "data", simple.Get("long", nil))
+	hit, err = os.ReadFile(c.FilePath("long"))
+	assert.NoError(t, err)
+	assert.Equal(t, data, hit)
+
+	saveSliding(t, c, "simple", 200*time.Millisecond, 0)
+	for i := 0; i < 3; i++ {
+		time.Sleep(120 * time.Millisecond)
+		assert.Equal(t, "data", simple.Get("simple", nil))
+	}
+	assert.WithinDuration(t, time.Now().Add(200*time.Millisecond), expirationOf(t, c.GetItem("simple")), 50*time.Millisecond)
+}
+
+func TestSliding_Chain(t *testing.T) {
+	fast, slow := cache.NewMemory(), cache.NewMemory()
+	chain := cache.NewChain(fast, slow)
+	saveSliding(t, slow, "session", time.Minute, time.Hour)
+
+	assert.NotNil(t, chain.GetItem("session"))
+	item := fast.GetItem("session")
+	assert.NotNil(t, item)
+	idle, maxExpiration := item.(cache.SlidingItem).GetIdleTimeout()
+	assert.Equal(t, time.Minute, idle)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), maxExpiration, time.Second)
+}
+
+// matchArgs matches first n arguments of command, rest of them (e.g. TTL) is ignored
+func matchArgs(n int) func(expected, actual []interface{}) error {
+	return func(expected, actual []interface{}) error {
+		if len(expected) < n || len(actual) < n || fmt.Sprint(expected[:n]) != fmt.Sprint(actual[:n]) {
+			return errors.New(fmt.Sprintf("expected %v, got %v", expected, actual))
+		}
+		return nil
+	}
+}
+
+func TestSliding_Redis(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	c := cache.NewRedisWithOptions(db, cache.RedisOptions{SlidingExpiration: true})
+
+	item := cache.NewRedisItem("s")
+	item.Set("data", time.Minute)
+	item.ExpiresAfterIdle(time.Minute, time.Hour)
+	_, maxExpiration := item.GetIdleTimeout()
+	idleValue := fmt.Sprintf("%d:%d", int64(time.Minute), maxExpiration.UnixNano())
+	mock.CustomMatch(matchArgs(3)).ExpectSet("s", "data", time.Minute).SetVal("OK")
+	mock.CustomMatch(matchArgs(3)).ExpectSet("__idle:s", idleValue, time.Minute).SetVal("OK")
+	assert.NoError(t, c.Save(item))
+
+	// hit pushes TTL of key and of its idle timeout forward
+	mock.ExpectGet("s").SetVal("data")
+	mock.ExpectGet("__idle:s").SetVal(fmt.Sprintf("%d:0", int64(time.Minute)))
+	mock.ExpectPExpire("s", time.Minute).SetVal(true)
+	mock.ExpectPExpire("__idle:s", time.Minute).SetVal(true)
+	found := c.GetItem("s")
+	assert.NotNil(t, found)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), expirationOf(t, found), time.Second)
+
+	// max expiration caps TTL
+	mock.ExpectGet("s").SetVal("data")
+	mock.ExpectGet("__idle:s").SetVal(fmt.Sprintf("%d:%d", int64(time.Minute), time.Now().Add(10*time.Second).UnixNano()))
+	mock.CustomMatch(matchArgs(2)).ExpectPExpire("s", 10*time.Second).SetVal(true)
+	mock.CustomMatch(matchArgs(2)).ExpectPExpire("__idle:s", 10*time.Second).SetVal(true)
+	found = c.GetItem("s")
+	assert.NotNil(t, found)
+	assert.WithinDuration(t, time.Now().Add(10*time.Second), expirationOf(t, found), time.Second)
+
+	// item without idle timeout doesn't slide
+	mock.ExpectGet("n").SetVal("data")
+	mock.ExpectGet("__idle:n").RedisNil()
+	assert.NotNil(t, c.GetItem("n"))
+
+	// GetItems and HasItem slide too
+	mock.ExpectGet("s").SetVal("data")
+	mock.ExpectGet("__idle:s").SetVal(fmt.Sprintf("%d:0", int64(time.Minute)))
+	mock.ExpectGet("m").RedisNil()
+	mock.ExpectPExpire("s", time.Minute).SetVal(true)
+	mock.ExpectPExpire("__idle:s", time.Minute).SetVal(true)
+	items := c.GetItems("s", "m")
+	assert.Len(t, items, 1)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), expirationOf(t, items[0]), time.Second)
+
+	mock.ExpectGet("s").SetVal("data")
+	mock.ExpectGet("__idle:s").SetVal(fmt.Sprintf("%d:0", int64(time.Minute)))
+	mock.ExpectPExpire("s", time.Minute).SetVal(true)
+	mock.ExpectPExpire("__idle:s", time.Minute).SetVal(true)
+	assert.True(t, c.HasItem("s"))
+
+	mock.ExpectDel("s", "__idle:s").SetVal(2)
+	assert.NoError(t, c.DeleteItem("s"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSliding_DSN(t *testing.T) {
+	_, err := cache.Open("redis://localhost:6379/0?sliding=true")
+	assert.NoError(t, err)
+	_, err = cache.Open("redis://localhost:6379/0?sliding=sometimes")
+	assert.ErrorIs(t, err, cache.ErrInvalidConfig)
+}
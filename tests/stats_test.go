@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"github.com/go-redis/redismock/v9"
+	"github.com/gouef/cache"
+	"github.com/gouef/standards"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestStatsCache(t *testing.T) {
+	t.Run("Counters", func(t *testing.T) {
+		memory := cache.NewMemoryWithOptions(cache.MemoryOptions{MaxEntries: 2})
+		c := cache.NewStatsCache(memory)
+		assert.Same(t, memory, c.Unwrap())
+
+		for _, key := range []string{"a", "b", "c"} {
+			item := c.NewItem(key)
+			item.Set(key, standards.KeepTTL)
+			assert.NoError(t, c.Save(item))
+		}
+		assert.Error(t, c.Save(cache.NewFileItem("d")))
+
+		assert.NotNil(t, c.GetItem("c"))
+		assert.Nil(t, c.GetItem("a"))
+		assert.True(t, c.HasItem("b"))
+		assert.Len(t, c.GetItems("b", "c", "x"), 2)
+
+		assert.NoError(t, c.DeleteItem("b"))
+		assert.NoError(t, c.DeleteItems("c", "x"))
+
+		expired := c.NewItem("expired")
+		expired.Set("data", time.Minute)
+		expired.ExpiresAt(time.Now().Add(-time.Second))
+		assert.NoError(t, c.SaveDeferred(expired))
+		assert.NoError(t, c.Commit())
+		purged, err := c.Purge()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, purged)
+
+		stats := c.Stats()
+		assert.Equal(t, uint64(4), stats.Hits)
+		assert.Equal(t, uint64(2), stats.Misses)
+		assert.Equal(t, uint64(4), stats.Sets)
+		assert.Equal(t, uint64(3), stats.Deletes)
+		assert.Equal(t, uint64(1), stats.Evictions)
+		assert.Equal(t, uint64(1), stats.Expirations)
+		assert.InDelta(t, 4.0/6.0, stats.HitRatio(), 0.0001)
+
+		assert.Equal(t, uint64(4), stats.GetLatency.Count)
+		assert.Equal(t, uint64(5), stats.SetLatency.Count)
+		assert.Equal(t, uint64(2), stats.DeleteLatency.Count)
+		assert.Equal(t, cache.DefaultLatencyBuckets, stats.GetLatency.Buckets)
+		assert.Len(t, stats.GetLatency.Counts, len(cache.DefaultLatencyBuckets)+1)
+		assert.Greater(t, stats.GetLatency.Mean(), time.Duration(0))
+	})
+
+	t.Run("Histogram buckets", func(t *testing.T) {
+		c := cache.NewStatsCacheWithBuckets(cache.NewMemory(), []time.Duration{0, time.Hour})
+		c.GetItem("a")
+		c.GetItem("b")
+
+		h := c.Stats().GetLatency
+		assert.Equal(t, []uint64{0, 2, 0}, h.Counts)
+		assert.Equal(t, uint64(2), h.Count)
+		assert.Equal(t, time.Duration(0), cache.Histogram{}.Mean())
+		assert.Equal(t, 0.0, cache.Stats{}.HitRatio())
+	})
+
+	t.Run("Backends without counters", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+		c := cache.NewStatsCache(cache.NewRedis(db))
+
+		mock.ExpectGet("a").RedisNil()
+		assert.Nil(t, c.GetItem("a"))
+		assert.NoError(t, c.Discard())
+		assert.NoError(t, c.InvalidateTags())
+		purged, err := c.Purge()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, purged)
+		assert.Equal(t, uint64(0), c.Stats().Evictions)
+		assert.Equal(t, uint64(1), c.Stats().Misses)
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		f := cache.NewStatsCache(&noFactoryCache{Memory: cache.NewMemory()})
+		assert.IsType(t, &cache.MemoryItem{}, f.NewItem("a"))
+	})
+
+	t.Run("Counters behind middleware", func(t *testing.T) {
+		s := cache.NewStorage()
+		s.Use(cache.PrefixMiddleware("app:"))
+		s.EnableStats()
+		c, err := s.AddMemoryWithOptions("users", cache.MemoryOptions{MaxEntries: 1})
+		assert.NoError(t, err)
+
+		for _, key := range []string{"a", "b", "c"} {
+			item := c.(cache.ItemFactory).NewItem(key)
+			item.Set("data", standards.KeepTTL)
+			assert.NoError(t, c.Save(item))
+		}
+		assert.Equal(t, uint64(2), s.Stats()["users"].Evictions)
+	})
+
+	t.Run("File expirations", func(t *testing.T) {
+		file, err := cache.NewFileWithOptions(t.TempDir(), cache.FileOptions{})
+		assert.NoError(t, err)
+		c := cache.NewStatsCache(file)
+
+		item := c.NewItem("a")
+		item.Set("data", time.Minute)
+		item.ExpiresAt(time.Now().Add(-time.Second))
+		assert.NoError(t, c.Save(item))
+		assert.Nil(t, c.GetItem("a"))
+		assert.NoError(t, c.Clear())
+		assert.Equal(t, uint64(1), c.Stats().Expirations)
+
+		simple, err := cache.NewFileSimple(t.TempDir())
+		assert.NoError(t, err)
+		assert.NoError(t, simple.SetMultiply(map[string]any{"a": "data"}, time.Nanosecond))
+		time.Sleep(time.Millisecond)
+		assert.Nil(t, simple.Get("a", nil))
+		assert.Equal(t, uint64(1), simple.Expirations())
+	})
+
+	t.Run("Storage", func(t *testing.T) {
+		s := cache.NewStorage()
+		a := cache.NewStatsCache(cache.NewMemory())
+		b := cache.NewStatsCacheWithBuckets(cache.NewMemory(), []time.Duration{time.Second})
+		_, _ = s.Add("a", a)
+		_, _ = s.Add("b", b)
+		_, _ = s.AddMemory("plain")
+
+		a.GetItem("x")
+		b.GetItem("x")
+		b.HasItem("y")
+
+		stats := s.Stats()
+		assert.Len(t, stats, 3)
+		assert.Equal(t, uint64(0), stats["plain"].Misses)
+		assert.Equal(t, uint64(1), stats["a"].Misses)
+		assert.Equal(t, uint64(2), stats["b"].Misses)
+
+		total := s.TotalStats()
+		assert.Equal(t, uint64(3), total.Misses)
+		assert.Equal(t, uint64(3), total.GetLatency.Count)
+		assert.Nil(t, total.GetLatency.Buckets)
+
+		same := a.Stats().Add(a.Stats())
+		assert.Equal(t, uint64(2), same.GetLatency.Count)
+		assert.Equal(t, cache.DefaultLatencyBuckets, same.GetLatency.Buckets)
+	})
+}
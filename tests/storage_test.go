@@ -1,9 +1,16 @@
 package tests
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/go-redis/redismock/v9"
 	"github.com/gouef/cache"
+	"github.com/gouef/standards"
 	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 )
 
@@ -41,3 +48,115 @@ func TestStorage(t *testing.T) {
 		assert.Nil(t, err)
 	})
 }
+
+// closingCache counts Close calls
+type closingCache struct {
+	*cache.Memory
+	closed int
+}
+
+func (c *closingCache) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestStorage_Lifecycle(t *testing.T) {
+	s := cache.NewStorage()
+	first := &closingCache{Memory: cache.NewMemory()}
+	_, err := s.Add("b", first, cache.PrefixMiddleware("b:"))
+	assert.NoError(t, err)
+	_, err = s.AddMemory("a")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, s.Names())
+
+	var visited []string
+	s.Range(func(name string, c standards.Cache) bool {
+		visited = append(visited, name)
+		return false
+	})
+	assert.Equal(t, []string{"a"}, visited)
+
+	second := &closingCache{Memory: cache.NewMemory()}
+	replaced, err := s.Replace("b", second)
+	assert.NoError(t, err)
+	got, _ := s.Get("b")
+	assert.Same(t, replaced, got)
+	assert.Equal(t, 1, first.closed, "replaced cache is closed, also decorated one")
+
+	replaced, err = s.Replace("b", second)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, second.closed, "same backend isn't closed")
+	replaced, err = s.Replace("b", cache.PrefixMiddleware("x:")(second))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, second.closed, "decorated backend isn't closed")
+	got, _ = s.Get("b")
+	assert.Same(t, replaced, got)
+
+	removed, exists := s.Remove("b")
+	assert.True(t, exists)
+	assert.Same(t, second, cache.UnwrapCache(removed))
+	assert.Equal(t, 0, second.closed, "removed cache isn't closed")
+	_, exists = s.Remove("b")
+	assert.False(t, exists)
+	assert.Equal(t, []string{"a"}, s.Names())
+
+	third := &closingCache{Memory: cache.NewMemory()}
+	_, err = s.Replace("c", third)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Close())
+	assert.Empty(t, s.Names())
+	assert.Equal(t, 1, third.closed)
+}
+
+func TestStorage_Ping(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "cache_ping_test")
+	_ = os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+
+	db, mock := redismock.NewClientMock()
+	s := cache.NewStorage()
+	_, _ = s.AddMemory("memory")
+	_, _ = s.AddFile("file", dir)
+	_, _ = s.AddRedis("redis", db, cache.StatsMiddleware())
+	_, _ = s.AddRedis("down", db)
+
+	mock.MatchExpectationsInOrder(false)
+	mock.ExpectPing().SetVal("PONG")
+	mock.ExpectPing().SetErr(errors.New("connection refused"))
+
+	status := s.Ping()
+	assert.Len(t, status, 4)
+	assert.NoError(t, status["memory"])
+	assert.NoError(t, status["file"])
+	failed := 0
+	for _, name := range []string{"redis", "down"} {
+		if status[name] != nil {
+			failed++
+			assert.ErrorIs(t, status[name], cache.ErrBackendUnavailable)
+		}
+	}
+	assert.Equal(t, 1, failed)
+
+	assert.NoError(t, os.RemoveAll(dir))
+	fileCache, _ := s.Get("file")
+	assert.ErrorIs(t, cache.UnwrapCache(fileCache).(cache.Pinger).PingContext(context.Background()), cache.ErrBackendUnavailable)
+}
+
+func TestStorage_Concurrent(t *testing.T) {
+	s := cache.NewStorage()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("cache-%d", i%5)
+			_, _ = s.AddMemory(name)
+			_, _ = s.Get(name)
+			_ = s.Names()
+			_ = s.Stats()
+			_, _ = s.Replace(name, cache.NewMemory())
+			s.Remove(name)
+		}(i)
+	}
+	wg.Wait()
+}
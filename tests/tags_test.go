@@ -0,0 +1,196 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/go-redis/redismock/v9"
+	"github.com/gouef/cache"
+	"github.com/gouef/standards"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTags(t *testing.T) {
+	t.Run("Items", func(t *testing.T) {
+		items := []cache.TaggedItem{
+			cache.NewMemoryItem("key"),
+			cache.NewFileItem("key"),
+			cache.NewRedisItem("key"),
+		}
+		for _, item := range items {
+			item.Tag("user:1", "product")
+			item.Tag("user:1")
+			assert.Equal(t, []string{"user:1", "product"}, item.GetTags())
+		}
+	})
+
+	t.Run("Memory", func(t *testing.T) {
+		memory := cache.NewMemory()
+
+		for key, tags := range map[string][]string{
+			"a": {"user:1"},
+			"b": {"user:1", "product:1"},
+			"c": {"product:2"},
+			"d": nil,
+		} {
+			item := cache.NewMemoryItem(key)
+			item.Set(key, standards.KeepTTL)
+			item.Tag(tags...)
+			assert.NoError(t, memory.Save(item))
+		}
+
+		assert.NoError(t, memory.InvalidateTags("user:1", "product:2"))
+		assert.Equal(t, 1, memory.Len())
+		assert.True(t, memory.HasItem("d"))
+	})
+
+	t.Run("File", func(t *testing.T) {
+		dir := t.TempDir()
+		c, err := cache.NewFileWithOptions(dir, cache.FileOptions{})
+		assert.NoError(t, err)
+
+		for key, tags := range map[string][]string{
+			"a": {"user:1"},
+			"b": {"user:1", "product:1"},
+			"c": {"product:1"},
+		} {
+			item := cache.NewFileItem(key)
+			item.Set(key, standards.KeepTTL)
+			item.Tag(tags...)
+			assert.NoError(t, c.Save(item))
+		}
+
+		// saved again without tag, so it is kept
+		item := cache.NewFileItem("a")
+		item.Set("a", standards.KeepTTL)
+		assert.NoError(t, c.Save(item))
+
+		assert.NoError(t, c.InvalidateTags("user:1", "missing"))
+		assert.True(t, c.HasItem("a"))
+		assert.False(t, c.HasItem("b"))
+		assert.True(t, c.HasItem("c"))
+
+		assert.NoError(t, c.Clear())
+		assert.NoDirExists(t, filepath.Join(dir, cache.TAGS_DIR))
+	})
+
+	t.Run("File index is compacted", func(t *testing.T) {
+		dir := t.TempDir()
+		c, err := cache.NewFileWithOptions(dir, cache.FileOptions{})
+		assert.NoError(t, err)
+		indexLines := func() int {
+			entries, _ := os.ReadDir(filepath.Join(dir, cache.TAGS_DIR))
+			lines := 0
+			for _, entry := range entries {
+				data, _ := os.ReadFile(filepath.Join(dir, cache.TAGS_DIR, entry.Name()))
+				lines += strings.Count(string(data), "\n")
+			}
+			return lines
+		}
+
+		for i := 0; i < 100; i++ {
+			item := cache.NewFileItem("a")
+			item.Set("a", standards.KeepTTL)
+			item.Tag("tag")
+			assert.NoError(t, c.Save(item))
+		}
+		assert.Equal(t, 1, indexLines())
+
+		assert.NoError(t, c.DeleteItem("a"))
+		assert.Equal(t, 0, indexLines())
+
+		// expired items are pruned by Purge
+		for _, key := range []string{"b", "c"} {
+			item := cache.NewFileItem(key)
+			item.Set(key, time.Millisecond)
+			item.ExpiresAfter(time.Millisecond)
+			item.Tag("tag")
+			assert.NoError(t, c.Save(item))
+		}
+		assert.Equal(t, 2, indexLines())
+		time.Sleep(5 * time.Millisecond)
+		purged, err := c.Purge()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, purged)
+		assert.Equal(t, 0, indexLines())
+	})
+
+	t.Run("File errors", func(t *testing.T) {
+		dir := t.TempDir()
+		c, err := cache.NewFileWithOptions(dir, cache.FileOptions{})
+		assert.NoError(t, err)
+
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, cache.TAGS_DIR), nil, 0644))
+		item := cache.NewFileItem("a")
+		item.Set("a", standards.KeepTTL)
+		item.Tag("tag")
+		assert.Error(t, c.Save(item))
+		assert.Error(t, c.InvalidateTags("tag"))
+	})
+
+	t.Run("Redis", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+		r := cache.NewRedis(db)
+
+		item, _ := cache.NewRedisItem("a").Set("data", standards.KeepTTL)
+		item.(cache.TaggedItem).Tag("user:1", "product:1")
+
+		mock.ExpectSet("a", "data", 0).SetVal("OK")
+		mock.ExpectEval(cache.REDIS_TAG_SCRIPT, []string{cache.REDIS_TAG_PREFIX + "user:1", cache.REDIS_TAG_PREFIX + "product:1"}, "a", int64(0)).SetVal(int64(0))
+		assert.NoError(t, r.Save(item))
+
+		// tag sets live at least as long as their items
+		expiring := cache.NewRedisItem("e")
+		expiring.Set("data", time.Minute)
+		expiring.ExpiresAfter(time.Minute)
+		expiring.Tag("user:1")
+		mock.CustomMatch(matchArgs(3)).ExpectSet("e", "data", time.Minute).SetVal("OK")
+		mock.CustomMatch(func(expected, actual []interface{}) error {
+			if err := matchArgs(5)(expected, actual); err != nil {
+				return err
+			}
+			if ttl, ok := actual[5].(int64); !ok || ttl < 59000 || ttl > 60000 {
+				return fmt.Errorf("unexpected TTL of tag set %v", actual[5])
+			}
+			return nil
+		}).ExpectEval(cache.REDIS_TAG_SCRIPT, []string{cache.REDIS_TAG_PREFIX + "user:1"}, "e", int64(0)).SetVal(int64(0))
+		assert.NoError(t, r.Save(expiring))
+
+		mock.ExpectSUnion(cache.REDIS_TAG_PREFIX + "user:1").SetVal([]string{"a", "b"})
+		mock.ExpectDel("a", "b", cache.REDIS_TAG_PREFIX+"user:1").SetVal(2)
+		assert.NoError(t, r.(cache.TagInvalidator).InvalidateTags("user:1"))
+		assert.NoError(t, r.(cache.TagInvalidator).InvalidateTags())
+
+		mock.ExpectSUnion(cache.REDIS_TAG_PREFIX + "user:2").RedisNil()
+		assert.Error(t, r.(cache.TagInvalidator).InvalidateTags("user:2"))
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Storage", func(t *testing.T) {
+		storage := cache.NewStorage()
+		memory, _ := storage.AddMemory("memory")
+		_, _ = storage.AddFile("file", t.TempDir())
+
+		item := cache.NewMemoryItem("a")
+		item.Set("a", standards.KeepTTL)
+		item.Tag("tag")
+		assert.NoError(t, memory.Save(item))
+
+		assert.NoError(t, storage.InvalidateTags("tag"))
+		assert.False(t, memory.HasItem("a"))
+	})
+}
+
+func TestTags_RedisExtraKeys(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	r := cache.NewRedisWithOptions(db, cache.RedisOptions{Prefix: "app:", XFetchBeta: cache.XFETCH_BETA, SlidingExpiration: true})
+
+	mock.ExpectSUnion("app:" + cache.REDIS_TAG_PREFIX + "tag").SetVal([]string{"app:a"})
+	mock.ExpectDel("app:a", "app:"+cache.REDIS_COMPUTE_PREFIX+"a", "app:"+cache.REDIS_IDLE_PREFIX+"a", "app:"+cache.REDIS_TAG_PREFIX+"tag").SetVal(3)
+	assert.NoError(t, r.InvalidateTags("tag"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"errors"
+	"github.com/go-redis/redismock/v9"
+	"github.com/gouef/cache"
+	"github.com/gouef/standards"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type typedUser struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func TestTyped(t *testing.T) {
+	t.Run("Memory", func(t *testing.T) {
+		memory := cache.NewMemory()
+		users := cache.NewTyped[typedUser](memory)
+		assert.Equal(t, memory, users.Cache())
+
+		_, found, err := users.Get("user")
+		assert.NoError(t, err)
+		assert.False(t, found)
+
+		assert.NoError(t, users.Set("user", typedUser{Name: "Jan", Age: 30}, time.Minute))
+
+		user, found, err := users.Get("user")
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, typedUser{Name: "Jan", Age: 30}, user)
+
+		assert.NoError(t, users.Delete("user"))
+		_, found, _ = users.Get("user")
+		assert.False(t, found)
+	})
+
+	t.Run("File", func(t *testing.T) {
+		file, err := cache.NewFile(t.TempDir())
+		assert.NoError(t, err)
+		users := cache.NewTyped[typedUser](file)
+
+		assert.NoError(t, users.Set("user", typedUser{Name: "Jan", Age: 30}, 0))
+
+		user, found, err := users.Get("user")
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, typedUser{Name: "Jan", Age: 30}, user)
+
+		names := cache.NewTyped[string](file)
+		assert.NoError(t, names.Set("name", "Jan", time.Minute))
+		name, found, err := names.Get("name")
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "Jan", name)
+
+		// value stored without Typed is decoded too
+		item := cache.NewFileItem("raw")
+		item.Set(map[string]any{"name": "Raw", "age": 1}, standards.KeepTTL)
+		assert.NoError(t, file.Save(item))
+		user, found, err = users.Get("raw")
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, typedUser{Name: "Raw", Age: 1}, user)
+
+		_, _, err = cache.NewTyped[int](file).Get("user")
+		assert.Error(t, err)
+	})
+
+	t.Run("Redis", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+		users := cache.NewTyped[typedUser](cache.NewRedis(db))
+
+		mock.ExpectSet("user", `{"name":"Jan","age":30}`, 0).SetVal("OK")
+		assert.NoError(t, users.Set("user", typedUser{Name: "Jan", Age: 30}, 0))
+
+		mock.ExpectGet("user").SetVal(`{"name":"Jan","age":30}`)
+		user, found, err := users.Get("user")
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, typedUser{Name: "Jan", Age: 30}, user)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("GetOrLoad", func(t *testing.T) {
+		counts := cache.NewTyped[int](cache.NewMemory())
+		calls := 0
+		loader := func() (int, error) {
+			calls++
+			return 42, nil
+		}
+
+		value, err := counts.GetOrLoad("answer", time.Minute, loader)
+		assert.NoError(t, err)
+		assert.Equal(t, 42, value)
+
+		value, err = counts.GetOrLoad("answer", time.Minute, loader)
+		assert.NoError(t, err)
+		assert.Equal(t, 42, value)
+		assert.Equal(t, 1, calls)
+
+		_, err = counts.GetOrLoad("error", time.Minute, func() (int, error) {
+			return 0, errors.New("load error")
+		})
+		assert.Error(t, err)
+		_, found, _ := counts.Get("error")
+		assert.False(t, found)
+	})
+
+	t.Run("Encode error", func(t *testing.T) {
+		file, err := cache.NewFile(t.TempDir())
+		assert.NoError(t, err)
+		assert.Error(t, cache.NewTyped[chan int](file).Set("chan", make(chan int), 0))
+	})
+}
@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"github.com/go-redis/redismock/v9"
+	"github.com/gouef/cache"
+	"github.com/gouef/standards"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// saveComputed saves item of key expiring after ttl which took computeTime to compute
+func saveComputed(t *testing.T, c standards.Cache, key string, ttl, computeTime time.Duration) {
+	item := c.(cache.ItemFactory).NewItem(key)
+	item.Set("data", ttl)
+	item.ExpiresAfter(ttl)
+	item.(cache.ComputeTimeItem).SetComputeTime(computeTime)
+	assert.NoError(t, c.Save(item))
+}
+
+func TestXFetch(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "cache_xfetch_test")
+	_ = os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+	fileCache, err := cache.NewFileWithOptions(dir, cache.FileOptions{XFetchBeta: cache.XFETCH_BETA})
+	assert.NoError(t, err)
+	plainFile, err := cache.NewFileWithOptions(filepath.Join(dir, "plain"), cache.FileOptions{})
+	assert.NoError(t, err)
+
+	for name, caches := range map[string][2]standards.Cache{
+		"memory": {cache.NewMemoryWithOptions(cache.MemoryOptions{XFetchBeta: cache.XFETCH_BETA}), cache.NewMemory()},
+		"file":   {fileCache, plainFile},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c, plain := caches[0], caches[1]
+
+			// computation is much longer than remaining time, so item expires early
+			saveComputed(t, c, "slow", time.Minute, 1000*time.Hour)
+			assert.Nil(t, c.GetItem("slow"))
+			assert.False(t, c.HasItem("slow"))
+
+			// far from expiration
+			saveComputed(t, c, "fast", time.Hour, time.Millisecond)
+			assert.NotNil(t, c.GetItem("fast"))
+			assert.Equal(t, time.Millisecond, c.GetItem("fast").(cache.ComputeTimeItem).GetComputeTime())
+
+			// without compute time or without XFetchBeta item expires at its expiration
+			saveComputed(t, c, "unknown", time.Minute, 0)
+			assert.NotNil(t, c.GetItem("unknown"))
+			saveComputed(t, plain, "slow", time.Minute, 1000*time.Hour)
+			assert.NotNil(t, plain.GetItem("slow"))
+
+			// probability of early expiration is exp(-remaining / (computeTime * beta))
+			saveComputed(t, c, "near", time.Hour, time.Hour)
+			early := 0
+			for i := 0; i < 1000; i++ {
+				if c.GetItem("near") == nil {
+					early++
+				}
+			}
+			assert.InDelta(t, 368, early, 120)
+		})
+	}
+}
+
+func TestXFetch_FileSimple(t *testing.T) {
+	dir := t.TempDir()
+	fileCache, err := cache.NewFile(dir)
+	assert.NoError(t, err)
+	saveComputed(t, fileCache, "slow", time.Minute, 1000*time.Hour)
+
+	simple, err := cache.NewFileSimpleWithOptions(dir, cache.FileOptions{XFetchBeta: cache.XFETCH_BETA})
+	assert.NoError(t, err)
+	_, err = simple.GetE("slow")
+	assert.ErrorIs(t, err, cache.ErrMiss)
+
+	plain, err := cache.NewFileSimple(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, "data", plain.Get("slow", nil))
+}
+
+func TestXFetch_ComputeTime(t *testing.T) {
+	c := cache.NewMemory()
+	_, err := cache.GetOrLoad(c, "a", time.Hour, func() (any, error) {
+		time.Sleep(5 * time.Millisecond)
+		return "data", nil
+	})
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, c.GetItem("a").(cache.ComputeTimeItem).GetComputeTime(), 5*time.Millisecond)
+
+	typed := cache.NewTyped[string](c)
+	_, err = typed.GetOrLoad("b", time.Hour, func() (string, error) {
+		time.Sleep(5 * time.Millisecond)
+		return "data", nil
+	})
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, c.GetItem("b").(cache.ComputeTimeItem).GetComputeTime(), 5*time.Millisecond)
+
+	chain := cache.NewChain(cache.NewMemory(), c)
+	assert.NotNil(t, chain.GetItem("a"))
+	assert.GreaterOrEqual(t, chain.Tiers()[0].GetItem("a").(cache.ComputeTimeItem).GetComputeTime(), 5*time.Millisecond)
+
+	prefixed := cache.NewPrefixCache(c, "p:")
+	_, err = cache.GetOrLoad(prefixed, "c", time.Hour, func() (any, error) {
+		time.Sleep(5 * time.Millisecond)
+		return "data", nil
+	})
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, c.GetItem("p:c").(cache.ComputeTimeItem).GetComputeTime(), 5*time.Millisecond)
+}
+
+func TestXFetch_Redis(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	c := cache.NewRedisWithOptions(db, cache.RedisOptions{XFetchBeta: cache.XFETCH_BETA})
+	slow := strconv.FormatInt(int64(1000*time.Hour), 10)
+
+	item, _ := cache.NewRedisItem("a").Set("data", standards.KeepTTL)
+	item.(cache.ComputeTimeItem).SetComputeTime(1000 * time.Hour)
+	mock.ExpectSet("a", "data", 0).SetVal("OK")
+	mock.ExpectSet("__compute:a", int64(1000*time.Hour), 0).SetVal("OK")
+	assert.NoError(t, c.Save(item))
+
+	mock.ExpectGet("a").SetVal("data")
+	mock.ExpectPTTL("a").SetVal(time.Minute)
+	mock.ExpectGet("__compute:a").SetVal(slow)
+	_, err := c.GetItemE("a")
+	assert.ErrorIs(t, err, cache.ErrMiss)
+
+	mock.ExpectGet("a").SetVal("data")
+	mock.ExpectPTTL("a").SetVal(time.Minute)
+	mock.ExpectGet("__compute:a").RedisNil()
+	found, err := c.GetItemE("a")
+	assert.NoError(t, err)
+	expiration, ok := found.(cache.ExpiringItem).GetExpiration()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), expiration, time.Second)
+
+	// GetItems and HasItem expire early too
+	mock.ExpectGet("a").SetVal("data")
+	mock.ExpectPTTL("a").SetVal(time.Minute)
+	mock.ExpectGet("__compute:a").SetVal(slow)
+	assert.Empty(t, c.GetItems("a"))
+	mock.ExpectGet("a").SetVal("data")
+	mock.ExpectPTTL("a").SetVal(time.Minute)
+	mock.ExpectGet("__compute:a").SetVal(slow)
+	assert.False(t, c.HasItem("a"))
+
+	mock.ExpectDel("a", "__compute:a").SetVal(2)
+	assert.NoError(t, c.DeleteItem("a"))
+
+	// keys of caller with spare capacity are not overwritten
+	keys := make([]string, 2, 4)
+	keys[0], keys[1] = "a", "b"
+	mock.ExpectDel("a", "b", "__compute:a", "__compute:b").SetVal(2)
+	assert.NoError(t, c.DeleteItems(keys...))
+	assert.Equal(t, []string{"a", "b", "", ""}, keys[:4])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestXFetch_DSN(t *testing.T) {
+	c, err := cache.Open("memory://?xfetch_beta=1.5")
+	assert.NoError(t, err)
+	saveComputed(t, c, "slow", time.Minute, 1000*time.Hour)
+	assert.Nil(t, c.GetItem("slow"))
+
+	_, err = cache.Open("memory://?xfetch_beta=-1")
+	assert.ErrorIs(t, err, cache.ErrInvalidConfig)
+	_, err = cache.Open("memory://?xfetch_beta=high")
+	assert.ErrorIs(t, err, cache.ErrInvalidConfig)
+}
@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"fmt"
+	"github.com/gouef/standards"
+	"time"
+)
+
+// Typed is type-safe wrapper over standards.Cache.
+// Values are stored encoded by codec in backends which don't keep Go values (File, Redis),
+// so they are read back as T.
+type Typed[T any] struct {
+	cache standards.Cache
+	codec Codec
+}
+
+// NewTyped create Typed instance with JSON codec
+func NewTyped[T any](cache standards.Cache) *Typed[T] {
+	return NewTypedWithCodec[T](cache, JSONCodec{})
+}
+
+// NewTypedWithCodec create Typed instance
+func NewTypedWithCodec[T any](cache standards.Cache, codec Codec) *Typed[T] {
+	return &Typed[T]{
+		cache: cache,
+		codec: codec,
+	}
+}
+
+// Cache returns wrapped cache
+func (t *Typed[T]) Cache() standards.Cache {
+	return t.cache
+}
+
+// Get returns value of key, second value is false when key is not in cache
+func (t *Typed[T]) Get(key string) (T, bool, error) {
+	var value T
+
+	item := t.cache.GetItem(key)
+	if item == nil || !item.IsHit() {
+		return value, false, nil
+	}
+
+	value, err := t.decode(item.Get())
+	if err != nil {
+		return value, false, fmt.Errorf("cache: decode %q: %w", key, err)
+	}
+	return value, true, nil
+}
+
+// Set store value, zero ttl means value never expires
+func (t *Typed[T]) Set(key string, value T, ttl time.Duration) error {
+	return t.set(key, value, ttl, 0)
+}
+
+// set store value with time which its computation took
+func (t *Typed[T]) set(key string, value T, ttl time.Duration, computeTime time.Duration) error {
+	stored, err := t.encode(value)
+	if err != nil {
+		return fmt.Errorf("cache: encode %q: %w", key, err)
+	}
+
+	item, err := newCacheItem(t.cache, key, stored, ttl)
+	if err != nil {
+		return err
+	}
+	setComputeTime(item, computeTime)
+	return t.cache.Save(item)
+}
+
+// GetOrLoad returns value of key, when key is not in cache it is loaded by loader and stored.
+// Concurrent misses of the same key are loaded only once per process.
+func (t *Typed[T]) GetOrLoad(key string, ttl time.Duration, loader func() (T, error)) (T, error) {
+	value, found, err := t.Get(key)
+	if err != nil || found {
+		return value, err
+	}
+
+	loaded, err := flights.Do(t.cache, key, func() (any, error) {
+		start := time.Now()
+		value, err := loader()
+		if err != nil {
+			return value, err
+		}
+		return value, t.set(key, value, ttl, time.Since(start))
+	})
+	value, _ = loaded.(T)
+	return value, err
+}
+
+// Delete remove key from cache
+func (t *Typed[T]) Delete(key string) error {
+	return t.cache.DeleteItem(key)
+}
+
+func (t *Typed[T]) encode(value T) (any, error) {
+	if keepsValues(t.cache) {
+		return value, nil
+	}
+
+	data, err := t.codec.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (t *Typed[T]) decode(stored any) (T, error) {
+	var value T
+
+	if keepsValues(t.cache) {
+		if v, ok := stored.(T); ok {
+			return v, nil
+		}
+	}
+
+	switch data := stored.(type) {
+	case string:
+		return value, t.codec.Unmarshal([]byte(data), &value)
+	case []byte:
+		return value, t.codec.Unmarshal(data, &value)
+	}
+
+	if v, ok := stored.(T); ok {
+		return v, nil
+	}
+
+	// value stored by other code, e.g. File returns decoded JSON
+	data, err := t.codec.Marshal(stored)
+	if err != nil {
+		return value, err
+	}
+	return value, t.codec.Unmarshal(data, &value)
+}
+
+// keepsValues reports if cache stores Go values without serialization
+func keepsValues(c standards.Cache) bool {
+	_, ok := findCache[*Memory](c)
+	return ok
+}
@@ -0,0 +1,213 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"github.com/gouef/standards"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MAX_KEY_LENGTH is maximum length of key in bytes accepted by ValidateKey
+const MAX_KEY_LENGTH = 1024
+
+// ValidateCache is decorator which rejects invalid keys,
+// reads of invalid key are misses and writes return error wrapping ErrInvalidKey.
+type ValidateCache struct {
+	cache    standards.Cache
+	validate func(key string) error
+}
+
+// NewValidateCache create ValidateCache decorator of cache, nil validate means ValidateKey
+func NewValidateCache(cache standards.Cache, validate func(key string) error) *ValidateCache {
+	if validate == nil {
+		validate = ValidateKey
+	}
+	return &ValidateCache{
+		cache:    cache,
+		validate: validate,
+	}
+}
+
+// ValidateMiddleware rejects invalid keys, nil validate means ValidateKey, see ValidateCache
+func ValidateMiddleware(validate func(key string) error) Middleware {
+	return func(cache standards.Cache) standards.Cache {
+		return NewValidateCache(cache, validate)
+	}
+}
+
+// ValidateKey rejects empty keys, keys longer than MAX_KEY_LENGTH, invalid UTF-8 and control characters
+func ValidateKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("%w: key is empty", ErrInvalidKey)
+	}
+	if len(key) > MAX_KEY_LENGTH {
+		return fmt.Errorf("%w: key is longer than %d bytes", ErrInvalidKey, MAX_KEY_LENGTH)
+	}
+	if !utf8.ValidString(key) {
+		return fmt.Errorf("%w: key %q is not valid UTF-8", ErrInvalidKey, key)
+	}
+	for _, r := range key {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("%w: key %q contains control character", ErrInvalidKey, key)
+		}
+	}
+	return nil
+}
+
+// Unwrap returns decorated cache
+func (c *ValidateCache) Unwrap() standards.Cache {
+	return c.cache
+}
+
+// NewItem create empty item of decorated cache
+func (c *ValidateCache) NewItem(key string) standards.CacheItem {
+	return newItemOf(c.cache, key)
+}
+
+func (c *ValidateCache) GetItem(key string) standards.CacheItem {
+	return c.GetItemContext(context.Background(), key)
+}
+
+func (c *ValidateCache) GetItemContext(ctx context.Context, key string) standards.CacheItem {
+	if c.validate(key) != nil {
+		return nil
+	}
+	return contextOf(c.cache).GetItemContext(ctx, key)
+}
+
+// GetItemE returns item of decorated cache, invalid key returns error wrapping ErrInvalidKey, see CheckedReader
+func (c *ValidateCache) GetItemE(key string) (standards.CacheItem, error) {
+	if err := c.validate(key); err != nil {
+		return nil, err
+	}
+	return getItemEOf(c.cache, key)
+}
+
+func (c *ValidateCache) GetItems(keys ...string) []standards.CacheItem {
+	return c.GetItemsContext(context.Background(), keys...)
+}
+
+func (c *ValidateCache) GetItemsContext(ctx context.Context, keys ...string) []standards.CacheItem {
+	valid := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if c.validate(key) == nil {
+			valid = append(valid, key)
+		}
+	}
+	if len(valid) == 0 {
+		return nil
+	}
+	return contextOf(c.cache).GetItemsContext(ctx, valid...)
+}
+
+func (c *ValidateCache) HasItem(key string) bool {
+	return c.HasItemContext(context.Background(), key)
+}
+
+func (c *ValidateCache) HasItemContext(ctx context.Context, key string) bool {
+	if c.validate(key) != nil {
+		return false
+	}
+	return contextOf(c.cache).HasItemContext(ctx, key)
+}
+
+// HasItemE checks item of decorated cache, invalid key returns error wrapping ErrInvalidKey, see CheckedReader
+func (c *ValidateCache) HasItemE(key string) (bool, error) {
+	if err := c.validate(key); err != nil {
+		return false, err
+	}
+	return hasItemEOf(c.cache, key)
+}
+
+func (c *ValidateCache) Clear() error {
+	return c.ClearContext(context.Background())
+}
+
+func (c *ValidateCache) ClearContext(ctx context.Context) error {
+	return contextOf(c.cache).ClearContext(ctx)
+}
+
+// ClearPrefix removes items with keys starting with prefix when decorated cache supports it
+func (c *ValidateCache) ClearPrefix(prefix string) error {
+	return clearPrefixOf(c.cache, prefix)
+}
+
+func (c *ValidateCache) DeleteItem(key string) error {
+	return c.DeleteItemContext(context.Background(), key)
+}
+
+func (c *ValidateCache) DeleteItemContext(ctx context.Context, key string) error {
+	if err := c.validate(key); err != nil {
+		return err
+	}
+	return contextOf(c.cache).DeleteItemContext(ctx, key)
+}
+
+func (c *ValidateCache) DeleteItems(keys ...string) error {
+	return c.DeleteItemsContext(context.Background(), keys...)
+}
+
+func (c *ValidateCache) DeleteItemsContext(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		if err := c.validate(key); err != nil {
+			return err
+		}
+	}
+	return contextOf(c.cache).DeleteItemsContext(ctx, keys...)
+}
+
+func (c *ValidateCache) Save(item standards.CacheItem) error {
+	return c.SaveContext(context.Background(), item)
+}
+
+func (c *ValidateCache) SaveContext(ctx context.Context, item standards.CacheItem) error {
+	if err := c.validate(item.GetKey()); err != nil {
+		return err
+	}
+	return contextOf(c.cache).SaveContext(ctx, item)
+}
+
+// SaveMany saves items at once when decorated cache supports it, nothing is saved when any key is invalid
+func (c *ValidateCache) SaveMany(items ...standards.CacheItem) error {
+	for _, item := range items {
+		if err := c.validate(item.GetKey()); err != nil {
+			return err
+		}
+	}
+	return saveManyOf(c.cache, items...)
+}
+
+func (c *ValidateCache) SaveDeferred(item standards.CacheItem) error {
+	return c.SaveDeferredContext(context.Background(), item)
+}
+
+func (c *ValidateCache) SaveDeferredContext(ctx context.Context, item standards.CacheItem) error {
+	if err := c.validate(item.GetKey()); err != nil {
+		return err
+	}
+	return contextOf(c.cache).SaveDeferredContext(ctx, item)
+}
+
+func (c *ValidateCache) Commit() error {
+	return c.CommitContext(context.Background())
+}
+
+func (c *ValidateCache) CommitContext(ctx context.Context) error {
+	return contextOf(c.cache).CommitContext(ctx)
+}
+
+// Discard drops pending items when decorated cache supports it
+func (c *ValidateCache) Discard() error {
+	return discardOf(c.cache)
+}
+
+// InvalidateTags removes items with tags when decorated cache supports tags
+func (c *ValidateCache) InvalidateTags(tags ...string) error {
+	return invalidateTagsOf(c.cache, tags...)
+}
+
+// Purge removes expired items when decorated cache supports it
+func (c *ValidateCache) Purge() (int, error) {
+	return purgeOf(c.cache)
+}
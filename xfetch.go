@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"github.com/gouef/standards"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// XFETCH_BETA is recommended beta of XFetch, higher beta recomputes values earlier
+const XFETCH_BETA = 1.0
+
+// ComputeTimeItem is item which records how long computation of its value took.
+// GetOrLoad, Typed.GetOrLoad and Refresher record it, so backends with XFetchBeta expire item early.
+type ComputeTimeItem interface {
+	SetComputeTime(d time.Duration)
+	GetComputeTime() time.Duration
+}
+
+// setComputeTime records compute time of item which supports it
+func setComputeTime(item standards.CacheItem, d time.Duration) {
+	if timed, ok := item.(ComputeTimeItem); ok && d > 0 {
+		timed.SetComputeTime(d)
+	}
+}
+
+// computeTimeOf returns compute time of item, 0 when it is unknown
+func computeTimeOf(item standards.CacheItem) time.Duration {
+	if timed, ok := item.(ComputeTimeItem); ok {
+		return timed.GetComputeTime()
+	}
+	return 0
+}
+
+// expiresEarly reports if item is treated as expired by XFetch (probabilistic early expiration):
+// item expires when now - computeTime * beta * ln(rand) reaches its expiration,
+// so probability rises near expiration and for values which take long to compute
+func expiresEarly(item standards.CacheItem, beta float64, now time.Time) bool {
+	if beta <= 0 {
+		return false
+	}
+	computeTime := computeTimeOf(item)
+	if computeTime <= 0 {
+		return false
+	}
+	expiring, ok := item.(ExpiringItem)
+	if !ok {
+		return false
+	}
+	expiration, ok := expiring.GetExpiration()
+	if !ok {
+		return false
+	}
+
+	// 1 - Float64 is in (0, 1], so logarithm is finite
+	gap := -float64(computeTime) * beta * math.Log(1-rand.Float64())
+	return gap >= float64(expiration.Sub(now))
+}